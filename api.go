@@ -6,11 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"maps"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+// BadStatusError is matched by every APIError, see APIError for details
 var BadStatusError = errors.New("bad HTTP Status Code")
 
 // Client is structure used to make calls to api easier
@@ -18,10 +21,56 @@ type Client struct {
 	http.Client
 	DefaultQuery url.Values
 	Domain       string
+	// Header is added to every request
+	Header http.Header
+	// MaxBodySize limits response bodies of API calls and MaxImageSize of downloads,
+	// 0 means DefaultMaxBodySize and DefaultMaxImageSize, negative values mean no limit
+	MaxBodySize  int64
+	MaxImageSize int64
+	// Middleware wraps every request sent by Client, see Use
+	Middleware []Middleware
+
+	// Logger receives a record about every API call and download, nil means no logging
+	Logger     *slog.Logger
+	LogOptions LogOptions
+	// Metrics receives measurements of every API call and download, nil means they aren't collected
+	Metrics Metrics
+	// Tracer starts a span for every API call and download, nil means NoopTracer
+	Tracer Tracer
+
+	// Retry is the policy used to retry failed requests, nil means no retries
+	Retry *RetryPolicy
+
+	// Limiter limits the rate of API calls, DownloadLimiter of image downloads, nil means no limit
+	Limiter         *RateLimiter
+	DownloadLimiter *RateLimiter
+
+	// Cache stores responses of GET calls, nil means no caching (random endpoints are never cached)
+	Cache Cache
+	// CacheTTL sets for how long responses are cached by endpoint template (i.e. TagByID),
+	// endpoints not present in it use DefaultCacheTTL
+	CacheTTL        map[string]time.Duration
+	DefaultCacheTTL time.Duration
+	// DownloadCache stores downloaded images, DownloadImage keys them by HashMD5 if the content matches it
+	// (so mirrors of an image share the entry),
+	// other downloads including DownloadSample are keyed by url
+	DownloadCache Cache
+	// Drift collects differences between responses and structs they're decoded into, nil means they aren't checked
+	Drift *DriftReport
+	// Strict makes calls with such differences fail with DriftError
+	Strict bool
+	// Offline makes Client serve everything from caches (even stale entries) without making requests,
+	// NotCachedError is returned for anything that isn't cached
+	Offline bool
 }
 
-func NewClient() *Client {
-	return &Client{Domain: DefaultDomain}
+// NewClient creates Client using DefaultDomain configured by given options
+func NewClient(opts ...Option) *Client {
+	c := &Client{Domain: DefaultDomain}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Get is a wrapper for GET http method
@@ -63,7 +112,114 @@ func (c *Client) CallAPI(method, path string, query url.Values, result interface
 // CallAPIWithContext is a plain api call
 //
 // At first it builds query suffix from provided url.Values and DefaultQuery, makes request, and marshals response data
+// (result can be nil when response data isn't needed)
 func (c *Client) CallAPIWithContext(ctx context.Context, method, path string, query url.Values, result interface{}) error {
+	r := &request{method: method, url: c.buildURL(path, query), path: path, query: query, endpoint: endpointOf(ctx, "", templateOf(path))}
+
+	ctx, start := c.begin(ctx, r)
+	body, err := c.call(ctx, r)
+	if err == nil {
+		err = unmarshal(body, result)
+	}
+	if err == nil {
+		err = c.checkDrift(r, body, result)
+	}
+	c.finish(ctx, r, start, len(body), body, err)
+	return err
+}
+
+// call makes the request using Cache and returns the response body
+func (c *Client) call(ctx context.Context, r *request) ([]byte, error) {
+	var key string
+	var cached *CacheEntry
+	if c.Cache != nil && r.method == http.MethodGet && cacheable(r.path) {
+		key = cacheKey(r.method, r.url)
+		r.cache = cacheMiss
+
+		var ok bool
+		if cached, ok = c.Cache.Get(key); ok {
+			if c.Offline || cached.Fresh(time.Now()) {
+				r.cache = cacheHit
+				return cached.Body, nil
+			}
+			if cached.ETag != "" {
+				r.header = http.Header{"If-None-Match": {cached.ETag}}
+			}
+		}
+	}
+	if c.Offline {
+		return nil, fmt.Errorf("%w: %s %s", NotCachedError, r.method, r.url)
+	}
+
+	response, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	if err = response.Body.Close(); err != nil {
+		return nil, err
+	}
+
+	if key != "" {
+		if response.StatusCode == http.StatusNotModified {
+			r.cache = cacheRevalidated
+			body = cached.Body
+			if response.Header.Get("ETag") == "" {
+				response.Header.Set("ETag", cached.ETag)
+			}
+		}
+		if ttl, ok := c.cacheTTL(r.path, response.Header); ok {
+			c.Cache.Set(key, newCacheEntry(body, response.Header, ttl, time.Now()))
+		} else {
+			c.Cache.Delete(key)
+		}
+	}
+	return body, nil
+}
+
+// begin starts span of the call, reports its start to Metrics and returns its start time
+func (c *Client) begin(ctx context.Context, r *request) (context.Context, time.Time) {
+	ctx, r.span = c.tracer().Start(ctx, spanName(r))
+	if c.Metrics != nil {
+		c.Metrics.Started(metricsEndpoint(r))
+	}
+	return ctx, time.Now()
+}
+
+// finish reports the finished call to Logger, Metrics and Tracer
+//
+// bytes is the amount of bytes read, body is the response body of API call (nil for downloads)
+func (c *Client) finish(ctx context.Context, r *request, start time.Time, bytes int, body []byte, err error) {
+	latency := time.Since(start)
+	c.logCall(ctx, r, latency, bytes, body, err)
+	c.endSpan(r, bytes, err)
+
+	if c.Metrics != nil {
+		c.Metrics.Finished(metricsEndpoint(r), CallMetrics{
+			Status:   r.status,
+			Latency:  latency,
+			Bytes:    bytes,
+			Attempts: r.attempts,
+			Err:      err,
+		})
+	}
+}
+
+// unmarshal is json.Unmarshal, which ignores data when there's no result to put it in
+func unmarshal(data []byte, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+// buildURL makes url for API call out of path, query and DefaultQuery
+func (c *Client) buildURL(path string, query url.Values) string {
 	var queryEnc string
 	if query == nil {
 		queryEnc = c.DefaultQuery.Encode()
@@ -79,26 +235,207 @@ func (c *Client) CallAPIWithContext(ctx context.Context, method, path string, qu
 	if queryEnc != "" {
 		reqPath += "?" + queryEnc
 	}
+	return reqPath
+}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqPath, http.NoBody)
-	if err != nil {
-		return err
+// request describes a single call made by Client
+type request struct {
+	method string
+	url    string
+
+	// path and query are the ones used to report errors
+	path  string
+	query url.Values
+
+	// download is set for image downloads, which use their own limiter
+	download bool
+	// noRedirect makes redirect responses returned instead of being followed
+	noRedirect bool
+	// header is added to the request, If-None-Match in it makes 304 response acceptable
+	header http.Header
+	// endpoint is given to middlewares through the request context
+	endpoint Endpoint
+
+	// attempts, status and cache describe how the request went, they're filled while it's made
+	attempts int
+	status   int
+	cache    string
+	// span traces the request, its context is propagated with traceparent header
+	span Span
+}
+
+// values of request.cache, empty one means that the request isn't cacheable
+const (
+	cacheMiss        = "miss"
+	cacheHit         = "hit"
+	cacheRevalidated = "revalidated"
+)
+
+// send makes the request, retrying it according to Retry policy
+//
+// it returns response only if it has status code accepted by the request, closing the body is the callers responsibility
+func (c *Client) send(ctx context.Context, r *request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		r.attempts = attempt
+		response, err := c.sendOnce(ctx, r)
+		if err == nil {
+			return response, nil
+		}
+
+		if !c.Retry.shouldRetry(r.method, attempt, err) {
+			return nil, err
+		}
+		if err = c.Retry.wait(ctx, attempt, err); err != nil {
+			return nil, err
+		}
 	}
+}
 
-	response, err := c.Do(req)
+// sendOnce makes a single attempt of the request
+func (c *Client) sendOnce(ctx context.Context, r *request) (*http.Response, error) {
+	limiter := c.Limiter
+	if r.download {
+		limiter = c.DownloadLimiter
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	endpoint := r.endpoint
+	endpoint.Query = r.query
+	endpoint.Download = r.download
+
+	req, err := http.NewRequestWithContext(ContextWithEndpoint(ctx, endpoint), r.method, r.url, http.NoBody)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
 	}
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: %s", BadStatusError, response.Status)
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	if r.span != nil {
+		injectTraceParent(req, r.span)
 	}
 
-	body, err := io.ReadAll(response.Body)
+	var doer Doer = &c.Client
+	if r.noRedirect {
+		doer = c.withoutRedirects()
+	}
+
+	response, err := c.chain(doer).Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err = response.Body.Close(); err != nil {
-		return err
+	limiter.Observe(response)
+	r.status = response.StatusCode
+
+	limit := c.bodyLimit(r.download)
+	if limit >= 0 && response.ContentLength > limit {
+		response.Body.Close()
+		return nil, r.tooLarge(limit)
+	}
+	response.Body = &limitedBody{ReadCloser: response.Body, remaining: limit, tooLarge: r.tooLarge(limit)}
+
+	if !r.accepts(response.StatusCode) {
+		defer response.Body.Close()
+		return nil, newAPIError(response, r.method, r.path, r.query)
+	}
+	return response, nil
+}
+
+// tooLarge returns error about response to the request exceeding limit
+func (r *request) tooLarge(limit int64) *ResponseTooLargeError {
+	return &ResponseTooLargeError{Method: r.method, Path: r.path, Limit: limit}
+}
+
+// accepts reports whether response with given status code is a successful one
+func (r *request) accepts(status int) bool {
+	switch {
+	case status == http.StatusOK:
+		return true
+	case status == http.StatusNotModified:
+		return r.header.Get("If-None-Match") != ""
+	default:
+		return r.noRedirect && isRedirect(status)
+	}
+}
+
+// withoutRedirects returns copy of http.Client that doesn't follow redirects
+func (c *Client) withoutRedirects() *http.Client {
+	client := c.Client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &client
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// default limits of response bodies, see Client.MaxBodySize and Client.MaxImageSize
+const (
+	DefaultMaxBodySize  = 16 << 20
+	DefaultMaxImageSize = 128 << 20
+)
+
+// maxDrain is the amount of unread body discarded on close, so the connection can be reused
+const maxDrain = 64 << 10
+
+// bodyLimit returns limit of response body size, negative one means there's no limit
+func (c *Client) bodyLimit(download bool) int64 {
+	if download {
+		if c.MaxImageSize == 0 {
+			return DefaultMaxImageSize
+		}
+		return c.MaxImageSize
+	}
+
+	if c.MaxBodySize == 0 {
+		return DefaultMaxBodySize
 	}
-	return json.Unmarshal(body, result)
+	return c.MaxBodySize
+}
+
+// limitedBody is response body, which fails to read beyond the limit and drains itself on Close
+type limitedBody struct {
+	io.ReadCloser
+	// remaining is the amount of bytes left to read, negative one means there's no limit
+	remaining int64
+	tooLarge  *ResponseTooLargeError
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return b.ReadCloser.Read(p)
+	}
+
+	if b.remaining == 0 {
+		// the limit is reached, but it's fine if nothing is left
+		var one [1]byte
+		n, err := b.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, b.tooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	_, _ = io.CopyN(io.Discard, b.ReadCloser, maxDrain)
+	return b.ReadCloser.Close()
 }
@@ -1,14 +1,20 @@
 package necos
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"maps"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestGet(t *testing.T) {
@@ -258,3 +264,113 @@ func TestQuery(t *testing.T) {
 		s.Close()
 	}()
 }
+
+func TestResponseTooLarge(t *testing.T) {
+	body := []byte(`{"id": 1, "name": "` + strings.Repeat("a", 100) + `"}`)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("chunked") {
+			// flushing before writing makes the response chunked, without Content-Length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write(body)
+	}))
+	defer s.Close()
+
+	var calls int
+	c := NewClient(WithDomain(s.URL), WithRetry(&RetryPolicy{MaxAttempts: 3, RetryableError: func(error) bool {
+		calls++
+		return true
+	}}))
+
+	for _, query := range []url.Values{nil, {"chunked": {"1"}}} {
+		c.MaxBodySize = int64(len(body))
+		var tag Tag
+		require.NoError(t, c.Get(Tags, query, &tag))
+		require.Equal(t, 1, tag.ID)
+
+		c.MaxBodySize = 50
+		err := c.Get(Tags, query, &tag)
+		require.ErrorIs(t, err, ErrResponseTooLarge)
+
+		var tooLarge *ResponseTooLargeError
+		require.ErrorAs(t, err, &tooLarge)
+		require.Equal(t, int64(50), tooLarge.Limit)
+		require.Equal(t, Tags, tooLarge.Path)
+
+		c.MaxImageSize = 10
+		var content []byte
+		require.ErrorIs(t, c.Download(context.Background(), s.URL+"?"+query.Encode(), SaveToSlice(&content)), ErrResponseTooLarge)
+
+		c.MaxImageSize = -1
+		content = nil
+		require.NoError(t, c.Download(context.Background(), s.URL+"?"+query.Encode(), SaveToSlice(&content)))
+		require.Equal(t, body, content)
+	}
+	require.Zero(t, calls)
+}
+
+func TestNoLeaksOnServerErrors(t *testing.T) {
+	errorBody := []byte(strings.Repeat(`{"detail": "Internal Server Error"}`, 1000))
+
+	var mu sync.Mutex
+	var conns, closed int
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write(errorBody)
+	}))
+	s.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch state {
+		case http.StateNew:
+			conns++
+		case http.StateClosed, http.StateHijacked:
+			closed++
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	const workers, calls = 4, 50
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// no connection is closed for exceeding the idle pool
+	transport.MaxIdleConnsPerHost = workers * calls
+	c := NewClient(WithDomain(s.URL), WithTransport(transport),
+		WithRetry(&RetryPolicy{MaxAttempts: 3, RetryableStatuses: []int{http.StatusInternalServerError}}))
+
+	goroutines := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range calls {
+				_, err := c.GetTags(nil)
+				assert.True(t, IsServerError(err))
+
+				var content []byte
+				assert.True(t, IsServerError(c.Download(context.Background(), s.URL+"/file.png", SaveToSlice(&content))))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// connections are put back to the pool asynchronously, so a request may dial a new one meanwhile,
+	// but bodies of failed responses are read to the end, so no connection is closed
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	require.Zero(t, closed, "%d connections closed of %d", closed, conns)
+	mu.Unlock()
+
+	// polling is done in the test goroutine, require.Eventually would add a goroutine of its own
+	transport.CloseIdleConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutines leaked: %d > %d\n%s", runtime.NumGoroutine(), goroutines, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,169 @@
+package necos
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores bodies of GET API responses, it must be safe for concurrent use
+//
+// keys are made of method and full url including merged DefaultQuery
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// CacheEntry is a cached response body
+type CacheEntry struct {
+	Body []byte
+	// ETag is used to revalidate stale entry with If-None-Match
+	ETag string
+	// Expires is the moment entry becomes stale, zero means it never does
+	Expires time.Time
+}
+
+// Fresh reports whether entry can be used without asking the server
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return e.Expires.IsZero() || now.Before(e.Expires)
+}
+
+// LRUCache is in-memory Cache keeping up to capacity the most recently used entries
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewLRUCache creates LRUCache with given capacity (at least 1)
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (c *LRUCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem{key: key, entry: entry})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of entries in cache
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// cacheKey makes Cache key for request
+func cacheKey(method, url string) string {
+	return method + " " + url
+}
+
+// uncachedEndpoints give different responses to the same requests, so they're never cached
+var uncachedEndpoints = map[string]bool{
+	RandomImages:    true,
+	RandomImageFile: true,
+}
+
+// cacheable tells whether responses of endpoint with given path can be cached
+func cacheable(path string) bool {
+	return !uncachedEndpoints[templateOf(path)]
+}
+
+// cacheTTL decides how long response of endpoint with given path should be cached,
+// Cache-Control of the response takes precedence over CacheTTL and DefaultCacheTTL
+//
+// returns false if response shouldn't be stored
+func (c *Client) cacheTTL(path string, header http.Header) (time.Duration, bool) {
+	ttl, ok := c.CacheTTL[templateOf(path)]
+	if !ok {
+		ttl = c.DefaultCacheTTL
+	}
+
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "private":
+			return 0, false
+		case "no-cache":
+			ttl = 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	// there's no point in keeping entry which can't be used or revalidated
+	if ttl <= 0 && header.Get("ETag") == "" {
+		return 0, false
+	}
+	return ttl, true
+}
+
+// newCacheEntry makes CacheEntry for response body expiring after ttl
+func newCacheEntry(body []byte, header http.Header, ttl time.Duration, now time.Time) *CacheEntry {
+	entry := &CacheEntry{Body: body, ETag: header.Get("ETag")}
+	entry.Expires = now.Add(max(ttl, time.Nanosecond))
+	return entry
+}
+
+// templateOf turns path back into endpoint template by replacing numeric segments with %d,
+// i.e. "/images/tags/12/images" becomes TagImages
+func templateOf(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if _, err := strconv.Atoi(s); err == nil {
+			segments[i] = "%d"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,140 @@
+package necos
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+
+	c.Set("a", &CacheEntry{Body: []byte("a")})
+	c.Set("b", &CacheEntry{Body: []byte("b")})
+
+	// touching "a" makes "b" the least recently used
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	c.Set("c", &CacheEntry{Body: []byte("c")})
+	require.Equal(t, 2, c.Len())
+
+	_, ok = c.Get("b")
+	require.False(t, ok)
+
+	entry, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, []byte("a"), entry.Body)
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestTemplateOf(t *testing.T) {
+	require.Equal(t, TagImages, templateOf(fmt.Sprintf(TagImages, 12)))
+	require.Equal(t, ArtistByID, templateOf(fmt.Sprintf(ArtistByID, 3)))
+	require.Equal(t, Images, templateOf(Images))
+}
+
+func TestClientCache(t *testing.T) {
+	t.Parallel()
+
+	var calls, revalidated atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch r.URL.Path {
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf("%q", r.URL.RawQuery)))
+	}))
+	defer s.Close()
+
+	c := Client{
+		Domain:       s.URL,
+		DefaultQuery: url.Values{"rating": {"safe"}},
+		Cache:        NewLRUCache(10),
+		CacheTTL:     map[string]time.Duration{TagByID: time.Hour},
+	}
+
+	get := func(path string, query url.Values) string {
+		var answer string
+		require.NoError(t, c.Get(path, query, &answer))
+		return answer
+	}
+
+	t.Run("ttl", func(t *testing.T) {
+		calls.Store(0)
+		path := fmt.Sprintf(TagByID, 1)
+
+		require.Equal(t, "rating=safe", get(path, nil))
+		require.Equal(t, "rating=safe", get(path, nil))
+		require.Equal(t, int32(1), calls.Load())
+
+		// key includes the merged query
+		require.Equal(t, "rating=explicit", get(path, url.Values{"rating": {"explicit"}}))
+		require.Equal(t, int32(2), calls.Load())
+
+		// endpoints without TTL aren't cached
+		get(Tags, nil)
+		get(Tags, nil)
+		require.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("no_store", func(t *testing.T) {
+		calls.Store(0)
+		c.DefaultCacheTTL = time.Hour
+		defer func() { c.DefaultCacheTTL = 0 }()
+
+		get("/no-store", nil)
+		get("/no-store", nil)
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("random", func(t *testing.T) {
+		calls.Store(0)
+		c.DefaultCacheTTL = time.Hour
+		defer func() { c.DefaultCacheTTL = 0 }()
+
+		// random endpoints give new results every time, so they aren't cached even with DefaultCacheTTL
+		get(RandomImages, nil)
+		get(RandomImages, nil)
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("etag", func(t *testing.T) {
+		calls.Store(0)
+
+		require.Equal(t, "rating=safe", get("/etag", nil))
+		require.Equal(t, "rating=safe", get("/etag", nil))
+		require.Equal(t, "rating=safe", get("/etag", nil))
+		require.Equal(t, int32(3), calls.Load())
+		require.Equal(t, int32(2), revalidated.Load())
+	})
+
+	t.Run("post", func(t *testing.T) {
+		calls.Store(0)
+		path := fmt.Sprintf(TagByID, 2)
+
+		var answer string
+		require.NoError(t, c.Post(path, nil, &answer))
+		require.NoError(t, c.Post(path, nil, &answer))
+		require.Equal(t, int32(2), calls.Load())
+	})
+}
@@ -0,0 +1,246 @@
+package necos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color is custom data used in parsing of colors, it's [r, g, b] with channels in [0..255]
+//
+// it implements color.Color, so it can be used with image packages directly
+type Color [3]int
+
+var _ color.Color = Color{}
+
+// ParseHex parses color given as "#rrggbb" or "#rgb" (# is optional)
+func ParseHex(s string) (Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return Color{}, fmt.Errorf("necos: invalid hex color %q", s)
+	}
+	return Color{int(value >> 16), int(value >> 8 & 0xff), int(value & 0xff)}, nil
+}
+
+// ColorOf converts any color.Color to Color, dropping alpha
+func ColorOf(c color.Color) Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return Color{int(n.R), int(n.G), int(n.B)}
+}
+
+// clamp returns channels of c limited to [0..255]
+func (c Color) clamp() (r, g, b uint8) {
+	channel := func(v int) uint8 {
+		return uint8(min(max(v, 0), 255))
+	}
+	return channel(c[0]), channel(c[1]), channel(c[2])
+}
+
+// Hex formats c as "#rrggbb"
+func (c Color) Hex() string {
+	r, g, b := c.clamp()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func (c Color) String() string {
+	return c.Hex()
+}
+
+// RGBA implements color.Color, Color is always opaque
+func (c Color) RGBA() (r, g, b, a uint32) {
+	r8, g8, b8 := c.clamp()
+	return color.NRGBA{R: r8, G: g8, B: b8, A: 0xff}.RGBA()
+}
+
+// HSL returns hue in degrees [0..360), saturation and lightness in [0..1]
+func (c Color) HSL() (h, s, l float64) {
+	r8, g8, b8 := c.clamp()
+	r, g, b := float64(r8)/255, float64(g8)/255, float64(b8)/255
+
+	high, low := max(r, g, b), min(r, g, b)
+	l = (high + low) / 2
+	if high == low {
+		return 0, 0, l
+	}
+
+	d := high - low
+	s = d / (1 - math.Abs(2*l-1))
+	switch high {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// FromHSL creates Color from hue in degrees, saturation and lightness in [0..1]
+func FromHSL(h, s, l float64) Color {
+	h = math.Mod(math.Mod(h, 360)+360, 360)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - chroma/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = chroma, x
+	case h < 120:
+		r, g = x, chroma
+	case h < 180:
+		g, b = chroma, x
+	case h < 240:
+		g, b = x, chroma
+	case h < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+
+	channel := func(v float64) int {
+		return int(math.Round((v + m) * 255))
+	}
+	return Color{channel(r), channel(g), channel(b)}
+}
+
+// Lab is a color in CIE L*a*b* space (D65 white point), where distances match perceived differences
+type Lab struct {
+	L, A, B float64
+}
+
+// Lab converts sRGB color c to CIE L*a*b*
+func (c Color) Lab() Lab {
+	r8, g8, b8 := c.clamp()
+	linear := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(r8), linear(g8), linear(b8)
+
+	// sRGB to XYZ relative to D65 white
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return Lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// Distance returns perceptual distance between colors (CIEDE2000), 0 means they're the same,
+// values below ~2.3 are barely noticeable
+func (c Color) Distance(other Color) float64 {
+	return CIEDE2000(c.Lab(), other.Lab())
+}
+
+// CIEDE2000 returns color difference of Lab colors as defined by CIEDE2000 formula
+func CIEDE2000(lab1, lab2 Lab) float64 {
+	const deg = math.Pi / 180
+	pow7 := func(v float64) float64 { return v * v * v * v * v * v * v }
+
+	c1 := math.Hypot(lab1.A, lab1.B)
+	c2 := math.Hypot(lab2.A, lab2.B)
+	meanC := (c1 + c2) / 2
+	g := 0.5 * (1 - math.Sqrt(pow7(meanC)/(pow7(meanC)+pow7(25))))
+
+	a1, a2 := (1+g)*lab1.A, (1+g)*lab2.A
+	c1, c2 = math.Hypot(a1, lab1.B), math.Hypot(a2, lab2.B)
+
+	hue := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / deg
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(lab1.B, a1), hue(lab2.B, a2)
+
+	dL := lab2.L - lab1.L
+	dC := c2 - c1
+
+	var dh float64
+	switch {
+	case c1*c2 == 0:
+		dh = 0
+	case math.Abs(h2-h1) <= 180:
+		dh = h2 - h1
+	case h2-h1 > 180:
+		dh = h2 - h1 - 360
+	default:
+		dh = h2 - h1 + 360
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(dh/2*deg)
+
+	meanL := (lab1.L + lab2.L) / 2
+	meanC = (c1 + c2) / 2
+
+	var meanH float64
+	switch {
+	case c1*c2 == 0:
+		meanH = h1 + h2
+	case math.Abs(h1-h2) <= 180:
+		meanH = (h1 + h2) / 2
+	case h1+h2 < 360:
+		meanH = (h1 + h2 + 360) / 2
+	default:
+		meanH = (h1 + h2 - 360) / 2
+	}
+
+	t := 1 - 0.17*math.Cos((meanH-30)*deg) + 0.24*math.Cos(2*meanH*deg) +
+		0.32*math.Cos((3*meanH+6)*deg) - 0.20*math.Cos((4*meanH-63)*deg)
+	dTheta := 30 * math.Exp(-math.Pow((meanH-275)/25, 2))
+	rc := 2 * math.Sqrt(pow7(meanC)/(pow7(meanC)+pow7(25)))
+	sl := 1 + 0.015*(meanL-50)*(meanL-50)/math.Sqrt(20+(meanL-50)*(meanL-50))
+	sc := 1 + 0.045*meanC
+	sh := 1 + 0.015*meanC*t
+	rt := -math.Sin(2*dTheta*deg) * rc
+
+	l, cc, hh := dL/sl, dC/sc, dH/sh
+	return math.Sqrt(l*l + cc*cc + hh*hh + rt*cc*hh)
+}
+
+// MarshalJSON encodes c as [r, g, b] array like API does
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]int(c))
+}
+
+// UnmarshalJSON decodes either [r, g, b] array or hex string
+func (c *Color) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '"' {
+		return json.Unmarshal(data, (*[3]int)(c))
+	}
+
+	var hex string
+	if err := json.Unmarshal(data, &hex); err != nil {
+		return err
+	}
+	parsed, err := ParseHex(hex)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
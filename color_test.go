@@ -0,0 +1,110 @@
+package necos
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"image/color"
+	"testing"
+)
+
+func TestColorHex(t *testing.T) {
+	c, err := ParseHex("#3a5F8c")
+	require.NoError(t, err)
+	require.Equal(t, Color{0x3a, 0x5f, 0x8c}, c)
+	require.Equal(t, "#3a5f8c", c.Hex())
+
+	c, err = ParseHex("f80")
+	require.NoError(t, err)
+	require.Equal(t, Color{0xff, 0x88, 0x00}, c)
+
+	for _, s := range []string{"", "#", "#12345", "#1234567", "#ggg", "+12345", "#-12345"} {
+		_, err = ParseHex(s)
+		require.Error(t, err, s)
+	}
+
+	require.Equal(t, "#00ff00", Color{-5, 300, 0}.Hex())
+}
+
+func TestColorInterface(t *testing.T) {
+	c := Color{10, 20, 30}
+	require.Equal(t, color.RGBAModel.Convert(color.RGBA{R: 10, G: 20, B: 30, A: 0xff}), color.RGBAModel.Convert(c))
+	require.Equal(t, c, ColorOf(c))
+	require.Equal(t, c, ColorOf(color.NRGBA{R: 10, G: 20, B: 30, A: 0x80}))
+	require.Equal(t, Color{0x80, 0x80, 0x80}, ColorOf(color.Gray{Y: 0x80}))
+}
+
+func TestColorHSL(t *testing.T) {
+	for _, test := range []struct {
+		c       Color
+		h, s, l float64
+	}{
+		{Color{255, 0, 0}, 0, 1, 0.5},
+		{Color{0, 255, 0}, 120, 1, 0.5},
+		{Color{0, 0, 255}, 240, 1, 0.5},
+		{Color{255, 255, 255}, 0, 0, 1},
+		{Color{0, 0, 0}, 0, 0, 0},
+		{Color{255, 0, 128}, 329.88, 1, 0.5},
+	} {
+		h, s, l := test.c.HSL()
+		require.InDelta(t, test.h, h, 0.01, test.c)
+		require.InDelta(t, test.s, s, 0.01, test.c)
+		require.InDelta(t, test.l, l, 0.01, test.c)
+		require.Equal(t, test.c, FromHSL(h, s, l))
+	}
+
+	require.Equal(t, Color{255, 0, 0}, FromHSL(-360, 1, 0.5))
+	for _, c := range []Color{{58, 95, 140}, {1, 2, 3}, {200, 199, 198}, {17, 250, 90}} {
+		require.Equal(t, c, FromHSL(c.HSL()))
+	}
+}
+
+func TestColorDistance(t *testing.T) {
+	// reference data from Sharma, Wu, Dalal "The CIEDE2000 Color-Difference Formula"
+	for _, test := range []struct {
+		a, b Lab
+		want float64
+	}{
+		{Lab{50, 2.6772, -79.7751}, Lab{50, 0, -82.7485}, 2.0425},
+		{Lab{50, 3.1571, -77.2803}, Lab{50, 0, -82.7485}, 2.8615},
+		{Lab{50, -1, 2}, Lab{50, 0, 0}, 2.3669},
+		{Lab{50, 2.5, 0}, Lab{73, 25, -18}, 27.1492},
+		{Lab{50, 2.5, 0}, Lab{50, 3.2592, 0.335}, 1.0000},
+		{Lab{60.2574, -34.0099, 36.2677}, Lab{60.4626, -34.1751, 39.4387}, 1.2644},
+		{Lab{22.7233, 20.0904, -46.694}, Lab{23.0331, 14.973, -42.5619}, 2.0373},
+		{Lab{90.8027, -2.0831, 1.441}, Lab{91.1528, -1.6435, 0.0447}, 1.4441},
+		{Lab{2.0776, 0.0795, -1.135}, Lab{0.9033, -0.0636, -0.5514}, 0.9082},
+	} {
+		require.InDelta(t, test.want, CIEDE2000(test.a, test.b), 1e-4, test)
+		require.InDelta(t, test.want, CIEDE2000(test.b, test.a), 1e-4, test)
+	}
+
+	red := Color{255, 0, 0}.Lab()
+	require.InDelta(t, 53.24, red.L, 0.01)
+	require.InDelta(t, 80.09, red.A, 0.01)
+	require.InDelta(t, 67.20, red.B, 0.01)
+	white := Color{255, 255, 255}.Lab()
+	require.InDelta(t, 100, white.L, 0.01)
+	require.InDelta(t, 0, white.A, 0.01)
+	require.InDelta(t, 0, white.B, 0.01)
+
+	require.Zero(t, Color{1, 2, 3}.Distance(Color{1, 2, 3}))
+	require.Less(t, Color{200, 10, 10}.Distance(Color{255, 0, 0}), Color{0, 0, 255}.Distance(Color{255, 0, 0}))
+}
+
+func TestColorJSON(t *testing.T) {
+	var im Image
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"color_dominant": "#3a5f8c",
+		"color_palette": [[1, 2, 3], "fff", null]
+	}`), &im))
+	require.Equal(t, Color{0x3a, 0x5f, 0x8c}, im.ColorDominant)
+	require.Equal(t, []Color{{1, 2, 3}, {255, 255, 255}, {}}, im.ColorPalette)
+
+	data, err := json.Marshal(Color{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, `[1,2,3]`, string(data))
+
+	var c Color
+	require.Error(t, json.Unmarshal([]byte(`"#zzz"`), &c))
+	require.Error(t, json.Unmarshal([]byte(`{"r": 1}`), &c))
+}
@@ -0,0 +1,205 @@
+package necos
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+const (
+	// DefaultColorThreshold is the maximum distance of matching images if ColorThreshold isn't given
+	DefaultColorThreshold = 10.0
+
+	// DefaultColorScanLimit is how many images client color searches look through if ScanLimit isn't given
+	DefaultColorScanLimit = 500
+)
+
+// ColorMatch is an image found by color search
+type ColorMatch struct {
+	Image Image
+
+	// Distance is the perceptual distance (CIEDE2000) between the image and the target colors
+	Distance float64
+}
+
+// ColorOption configures color search
+type ColorOption func(*colorConfig)
+
+type colorConfig struct {
+	threshold  float64
+	maxResults int
+	palette    bool
+	scanLimit  int
+}
+
+func newColorConfig(opts []ColorOption) colorConfig {
+	cfg := colorConfig{
+		threshold: DefaultColorThreshold,
+		scanLimit: DefaultColorScanLimit,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// ColorThreshold sets the maximum distance of matching images, negative threshold means any distance matches
+func ColorThreshold(d float64) ColorOption {
+	return func(cfg *colorConfig) {
+		cfg.threshold = d
+	}
+}
+
+// MaxResults keeps only n closest images, n <= 0 means no limit
+func MaxResults(n int) ColorOption {
+	return func(cfg *colorConfig) {
+		cfg.maxResults = n
+	}
+}
+
+// ComparePalette makes search compare targets with the whole ColorPalette of images, not only ColorDominant
+func ComparePalette() ColorOption {
+	return func(cfg *colorConfig) {
+		cfg.palette = true
+	}
+}
+
+// ScanLimit sets how many images client searches look through, n <= 0 means no limit
+//
+// it's ignored by SearchByColor and RankByColor since they are limited by the given images
+func ScanLimit(n int) ColorOption {
+	return func(cfg *colorConfig) {
+		cfg.scanLimit = n
+	}
+}
+
+// ColorDistance returns how far image is from targets
+//
+// for each target the closest of image colors is taken and the result is the mean of their distances,
+// image colors are ColorDominant and, if palette is true, ColorPalette
+func ColorDistance(im Image, targets []Color, palette bool) float64 {
+	if len(targets) == 0 {
+		return 0
+	}
+
+	colors := []Color{im.ColorDominant}
+	if palette {
+		colors = append(colors, im.ColorPalette...)
+	}
+
+	var sum float64
+	for _, target := range targets {
+		closest := target.Distance(colors[0])
+		for _, c := range colors[1:] {
+			closest = min(closest, target.Distance(c))
+		}
+		sum += closest
+	}
+	return sum / float64(len(targets))
+}
+
+// colorRanking collects matches of color search
+type colorRanking struct {
+	cfg     colorConfig
+	targets []Color
+	matches []ColorMatch
+}
+
+func (r *colorRanking) add(im Image) {
+	d := ColorDistance(im, r.targets, r.cfg.palette)
+	if r.cfg.threshold < 0 || d <= r.cfg.threshold {
+		r.matches = append(r.matches, ColorMatch{Image: im, Distance: d})
+	}
+}
+
+// result returns matches from the closest to the farthest
+func (r *colorRanking) result() []ColorMatch {
+	slices.SortStableFunc(r.matches, func(a, b ColorMatch) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Image.ID, b.Image.ID))
+	})
+	if r.cfg.maxResults > 0 && len(r.matches) > r.cfg.maxResults {
+		r.matches = r.matches[:r.cfg.maxResults]
+	}
+	return r.matches
+}
+
+// RankByColor returns images which are close to targets, from the closest to the farthest
+//
+// for a single target give one color, for a palette give several of them (see ColorDistance)
+func RankByColor(images []Image, targets []Color, opts ...ColorOption) []ColorMatch {
+	r := colorRanking{cfg: newColorConfig(opts), targets: targets}
+	for _, im := range images {
+		r.add(im)
+	}
+	return r.result()
+}
+
+// SearchByColor ranks images of seq like RankByColor does
+//
+// it's meant to be used with iterators like AllImages, on error it returns matches found so far with the error
+func SearchByColor(seq iter.Seq2[Image, error], targets []Color, opts ...ColorOption) ([]ColorMatch, error) {
+	r := colorRanking{cfg: newColorConfig(opts), targets: targets}
+	for im, err := range seq {
+		if err != nil {
+			return r.result(), err
+		}
+		r.add(im)
+	}
+	return r.result(), nil
+}
+
+// SearchImagesByColor pages through Images endpoint looking for images close to targets
+//
+// it looks through ScanLimit images at most (DefaultColorScanLimit by default),
+// for more info on Request parameters see GetImages
+func (c *Client) SearchImagesByColor(ctx context.Context, req Request, targets []Color, opts ...ColorOption) ([]ColorMatch, error) {
+	cfg := newColorConfig(opts)
+	return SearchByColor(c.AllImages(ctx, req, MaxItems(cfg.scanLimit)), targets, opts...)
+}
+
+// SearchRandomImagesByColor requests RandomImages endpoint until ScanLimit distinct images are seen
+// and ranks them like SearchByColor does
+//
+// it stops earlier if a response has no new images, so it can't loop forever on small selections,
+// for more info on Request parameters see GetRandomImages
+func (c *Client) SearchRandomImagesByColor(ctx context.Context, req Request, targets []Color, opts ...ColorOption) ([]ColorMatch, error) {
+	cfg := newColorConfig(opts)
+	if cfg.scanLimit <= 0 {
+		// random images never end
+		cfg.scanLimit = DefaultColorScanLimit
+	}
+
+	return SearchByColor(func(yield func(Image, error) bool) {
+		query := maps.Clone(req)
+		if query == nil {
+			query = Request{}
+		}
+
+		seen := make(map[int]bool)
+		for len(seen) < cfg.scanLimit {
+			query.Set("limit", strconv.Itoa(min(cfg.scanLimit-len(seen), MaxPageSize)))
+			page, err := c.GetRandomImagesWithContext(ctx, query)
+			if err != nil {
+				yield(Image{}, err)
+				return
+			}
+
+			fresh := false
+			for _, im := range page.Items {
+				if seen[im.ID] || len(seen) >= cfg.scanLimit {
+					continue
+				}
+				seen[im.ID], fresh = true, true
+				if !yield(im, nil) {
+					return
+				}
+			}
+			if !fresh {
+				return
+			}
+		}
+	}, targets, opts...)
+}
@@ -0,0 +1,113 @@
+package necos
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var searchImages = []Image{
+	{ID: 1, ColorDominant: Color{0x3a, 0x5f, 0x8c}, ColorPalette: []Color{{250, 250, 250}}},
+	{ID: 2, ColorDominant: Color{255, 0, 0}, ColorPalette: []Color{{0x3b, 0x5f, 0x8d}, {0, 0, 0}}},
+	{ID: 3, ColorDominant: Color{0x40, 0x60, 0x90}, ColorPalette: []Color{{0, 0, 0}}},
+	{ID: 4, ColorDominant: Color{0, 255, 0}},
+	{ID: 5, ColorDominant: Color{0x3a, 0x5f, 0x8c}},
+}
+
+func matchIDs(matches []ColorMatch) []int {
+	ids := make([]int, len(matches))
+	for i, m := range matches {
+		ids[i] = m.Image.ID
+	}
+	return ids
+}
+
+func TestRankByColor(t *testing.T) {
+	target := []Color{{0x3a, 0x5f, 0x8c}}
+
+	matches := RankByColor(searchImages, target)
+	require.Equal(t, []int{1, 5, 3}, matchIDs(matches))
+	require.Zero(t, matches[0].Distance)
+	require.Less(t, matches[2].Distance, DefaultColorThreshold)
+
+	require.Equal(t, []int{1, 5, 2, 3}, matchIDs(RankByColor(searchImages, target, ComparePalette())))
+	require.Equal(t, []int{1, 5}, matchIDs(RankByColor(searchImages, target, ColorThreshold(0.5))))
+	require.Equal(t, []int{1}, matchIDs(RankByColor(searchImages, target, MaxResults(1))))
+	require.Len(t, RankByColor(searchImages, target, ColorThreshold(-1)), len(searchImages))
+
+	// palette targets match images having all of the colors
+	palette := []Color{{0x3a, 0x5f, 0x8c}, {0, 0, 0}}
+	require.Equal(t, []int{2, 3}, matchIDs(RankByColor(searchImages, palette, ComparePalette(), ColorThreshold(5))))
+	require.Zero(t, ColorDistance(searchImages[0], nil, false))
+}
+
+func TestSearchByColor(t *testing.T) {
+	seq := func(yield func(Image, error) bool) {
+		for _, im := range searchImages[:3] {
+			if !yield(im, nil) {
+				return
+			}
+		}
+		yield(Image{}, BadStatusError)
+	}
+
+	matches, err := SearchByColor(seq, []Color{{0x3a, 0x5f, 0x8c}})
+	require.ErrorIs(t, err, BadStatusError)
+	require.Equal(t, []int{1, 3}, matchIDs(matches))
+}
+
+func TestSearchImagesByColor(t *testing.T) {
+	var calls atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		page := MultipleContainer[Image]{Count: len(searchImages), Items: []Image{}}
+		if r.URL.Path == RandomImages {
+			// two images at most, each response starts where the previous one ended
+			start := 2 * int(calls.Load()-1)
+			for i := range min(limit, 2) {
+				page.Items = append(page.Items, searchImages[(start+i)%len(searchImages)])
+			}
+		} else {
+			page.Items = append(page.Items, searchImages[min(offset, len(searchImages)):min(offset+limit, len(searchImages))]...)
+		}
+		data, _ := json.Marshal(page)
+		_, _ = w.Write(data)
+	}))
+	defer s.Close()
+
+	// random images aren't cached, so every response can bring new ones
+	c := &Client{Domain: s.URL, Cache: NewLRUCache(10), DefaultCacheTTL: time.Hour}
+	target := []Color{{0x3a, 0x5f, 0x8c}}
+
+	matches, err := c.SearchImagesByColor(context.Background(), nil, target)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 5, 3}, matchIDs(matches))
+
+	calls.Store(0)
+	matches, err = c.SearchImagesByColor(context.Background(), nil, target, ScanLimit(2))
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, matchIDs(matches))
+	require.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	matches, err = c.SearchRandomImagesByColor(context.Background(), nil, target, MaxResults(2))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 5}, matchIDs(matches))
+	// the fourth response has no new images
+	require.Equal(t, int32(4), calls.Load())
+
+	calls.Store(0)
+	matches, err = c.SearchRandomImagesByColor(context.Background(), nil, target, ScanLimit(3), ColorThreshold(-1))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3, 2}, matchIDs(matches))
+	require.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,278 @@
+package necos
+
+import (
+	"bufio"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	diskCacheExt    = ".entry"
+	diskCacheTmp    = ".tmp-"
+	imageKeyPrefix  = "md5:"
+	imageFilePrefix = "md5-"
+)
+
+// NotCachedError is returned by Client in Offline mode when there's nothing in cache for the request
+var NotCachedError = errors.New("not found in cache while offline")
+
+// imageCacheKey makes cache key for image content with given md5 hash
+func imageCacheKey(hashMD5 string) string {
+	return imageKeyPrefix + strings.ToLower(hashMD5)
+}
+
+// DiskCache is Cache storing entries as files in directory, so they survive restarts
+//
+// images downloaded with DownloadImage are stored by their HashMD5 and JSON responses by request key.
+// Every entry is written to temporary file and renamed, so crash never leaves half-written entry.
+// When total size exceeds the limit, the least recently used entries are removed.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List
+	items map[string]*list.Element
+}
+
+type diskItem struct {
+	name string
+	size int64
+}
+
+// diskHeader is the first line of the entry file, the rest of it is the body
+type diskHeader struct {
+	Key     string
+	ETag    string    `json:",omitempty"`
+	Expires time.Time `json:",omitempty"`
+}
+
+// NewDiskCache opens (creating if needed) DiskCache in dir, maxBytes <= 0 means no size limit
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load builds LRU index out of files in directory using their modification time
+func (c *DiskCache) load() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		diskItem
+		used time.Time
+	}
+	files := make([]file, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, diskCacheTmp) {
+			// leftover of interrupted write
+			_ = os.Remove(filepath.Join(c.dir, name))
+			continue
+		}
+		if e.IsDir() || !strings.HasSuffix(name, diskCacheExt) {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{diskItem{name: name, size: info.Size()}, info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].used.After(files[j].used)
+	})
+	for _, f := range files {
+		c.items[f.name] = c.order.PushBack(&diskItem{name: f.name, size: f.size})
+		c.size += f.size
+	}
+
+	c.evict()
+	return nil
+}
+
+// fileName makes name of the entry file for key
+func fileName(key string) string {
+	if hash, ok := strings.CutPrefix(key, imageKeyPrefix); ok && isHex(hash) {
+		return imageFilePrefix + hash + diskCacheExt
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + diskCacheExt
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil && s != ""
+}
+
+func (c *DiskCache) Get(key string) (*CacheEntry, bool) {
+	name := fileName(key)
+
+	f, err := os.Open(filepath.Join(c.dir, name))
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, false
+	}
+
+	var header diskHeader
+	if err = json.Unmarshal(line, &header); err != nil || header.Key != key {
+		return nil, false
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+
+	c.touch(name)
+	return &CacheEntry{Body: body, ETag: header.ETag, Expires: header.Expires}, true
+}
+
+// touch marks entry as recently used, both in index and on disk
+func (c *DiskCache) touch(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[name]; ok {
+		c.order.MoveToFront(el)
+	}
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(c.dir, name), now, now)
+}
+
+func (c *DiskCache) Set(key string, entry *CacheEntry) {
+	name := fileName(key)
+
+	tmp, size, err := c.writeTemp(key, entry)
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp)
+
+	// renaming under the lock keeps the file and the index in sync with concurrent Delete and evict
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err = os.Rename(tmp, filepath.Join(c.dir, name)); err != nil {
+		return
+	}
+	if el, ok := c.items[name]; ok {
+		item := el.Value.(*diskItem)
+		c.size += size - item.size
+		item.size = size
+		c.order.MoveToFront(el)
+	} else {
+		c.items[name] = c.order.PushFront(&diskItem{name: name, size: size})
+		c.size += size
+	}
+	c.evict()
+}
+
+// writeTemp writes entry to temporary file, which is renamed to the entry file by Set,
+// and returns its path and size
+func (c *DiskCache) writeTemp(key string, entry *CacheEntry) (string, int64, error) {
+	header, err := json.Marshal(diskHeader{Key: key, ETag: entry.ETag, Expires: entry.Expires})
+	if err != nil {
+		return "", 0, err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, diskCacheTmp+"*")
+	if err != nil {
+		return "", 0, err
+	}
+
+	w := bufio.NewWriter(tmp)
+	_, _ = w.Write(header)
+	_ = w.WriteByte('\n')
+	_, _ = w.Write(entry.Body)
+	if err = w.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	return tmp.Name(), int64(len(header) + 1 + len(entry.Body)), nil
+}
+
+func (c *DiskCache) Delete(key string) {
+	name := fileName(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(name)
+}
+
+// remove deletes the entry file, c.mu must be held
+func (c *DiskCache) remove(name string) {
+	if el, ok := c.items[name]; ok {
+		c.size -= el.Value.(*diskItem).size
+		c.order.Remove(el)
+		delete(c.items, name)
+	}
+	_ = os.Remove(filepath.Join(c.dir, name))
+}
+
+// evict removes the least recently used entries until size fits the limit, c.mu must be held
+func (c *DiskCache) evict() {
+	if c.maxBytes <= 0 {
+		return
+	}
+
+	for c.size > c.maxBytes && c.order.Len() > 0 {
+		c.remove(c.order.Back().Value.(*diskItem).name)
+	}
+}
+
+// Size returns total size of entries in bytes
+func (c *DiskCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// Len returns the number of entries in cache
+func (c *DiskCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,179 @@
+package necos
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := NewDiskCache(dir, 0)
+	require.NoError(t, err)
+
+	expires := time.Now().Add(time.Hour).Round(0)
+	c.Set("GET /images", &CacheEntry{Body: []byte(`{"count": 0}`), ETag: `"x"`, Expires: expires})
+	c.Set(imageCacheKey("ABCDEF"), &CacheEntry{Body: []byte("image")})
+	require.Equal(t, 2, c.Len())
+
+	// images are content-addressed
+	_, err = os.Stat(filepath.Join(dir, "md5-abcdef"+diskCacheExt))
+	require.NoError(t, err)
+
+	// leftovers of interrupted writes are removed on open
+	require.NoError(t, os.WriteFile(filepath.Join(dir, diskCacheTmp+"123"), []byte("junk"), 0o644))
+
+	reopened, err := NewDiskCache(dir, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, reopened.Len())
+	require.Equal(t, c.Size(), reopened.Size())
+
+	entry, ok := reopened.Get("GET /images")
+	require.True(t, ok)
+	require.Equal(t, []byte(`{"count": 0}`), entry.Body)
+	require.Equal(t, `"x"`, entry.ETag)
+	require.True(t, expires.Equal(entry.Expires))
+
+	_, err = os.Stat(filepath.Join(dir, diskCacheTmp+"123"))
+	require.True(t, os.IsNotExist(err))
+
+	reopened.Delete("GET /images")
+	_, ok = reopened.Get("GET /images")
+	require.False(t, ok)
+	require.Equal(t, 1, reopened.Len())
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 300)
+	require.NoError(t, err)
+
+	body := make([]byte, 80)
+	c.Set("a", &CacheEntry{Body: body})
+	c.Set("b", &CacheEntry{Body: body})
+
+	// touching "a" makes "b" the least recently used
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	c.Set("c", &CacheEntry{Body: body})
+	require.LessOrEqual(t, c.Size(), int64(300))
+
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	_, ok = c.Get("a")
+	require.True(t, ok)
+	_, ok = c.Get("c")
+	require.True(t, ok)
+}
+
+func TestDiskCacheConcurrentSetDelete(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, 1000)
+	require.NoError(t, err)
+
+	// after every round of racing Set and Delete the index must match files in the directory
+	for round := range 300 {
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if (round+i)%2 == 0 {
+					c.Set("key", &CacheEntry{Body: make([]byte, 10+i)})
+				} else {
+					c.Delete("key")
+				}
+			}()
+		}
+		wg.Wait()
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var size int64
+		for _, e := range entries {
+			info, err := e.Info()
+			require.NoError(t, err)
+			size += info.Size()
+		}
+		require.Equal(t, len(entries), c.Len(), round)
+		require.Equal(t, size, c.Size(), round)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/image.webp" {
+			_, _ = w.Write([]byte("image"))
+			return
+		}
+		_, _ = w.Write([]byte(`"ok"`))
+	}))
+	defer s.Close()
+
+	cache, err := NewDiskCache(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	c := Client{
+		Domain:          s.URL,
+		Cache:           cache,
+		DefaultCacheTTL: time.Nanosecond,
+		DownloadCache:   cache,
+	}
+
+	var answer string
+	require.NoError(t, c.Get(Tags, nil, &answer))
+
+	hash := md5.Sum([]byte("image"))
+	im := Image{ImageURL: s.URL + "/image.webp", HashMD5: hex.EncodeToString(hash[:])}
+	var content []byte
+	require.NoError(t, c.DownloadImage(&im, SaveToSlice(&content)))
+	require.Equal(t, "image", string(content))
+
+	// content not matching its hash is cached only by url
+	wrong := Image{ImageURL: s.URL + "/image.webp?wrong", HashMD5: "0123456789abcdef0123456789abcdef"}
+	require.NoError(t, c.DownloadImage(&wrong, SaveToSlice(&content)))
+	_, ok := cache.Get(imageCacheKey(wrong.HashMD5))
+	require.False(t, ok)
+	require.Equal(t, int32(3), calls.Load())
+
+	c.Offline = true
+
+	// stale entries are served while offline
+	answer = ""
+	require.NoError(t, c.Get(Tags, nil, &answer))
+	require.Equal(t, "ok", answer)
+
+	// same image is found by hash even under different url
+	im.ImageURL = s.URL + "/mirror.webp"
+	content = nil
+	require.NoError(t, c.DownloadImage(&im, SaveToSlice(&content)))
+	require.Equal(t, "image", string(content))
+
+	// wrong content is served only for its own url
+	content = nil
+	require.NoError(t, c.DownloadImage(&wrong, SaveToSlice(&content)))
+	require.Equal(t, "image", string(content))
+	wrong.ImageURL = s.URL + "/mirror.webp?wrong"
+	require.ErrorIs(t, c.DownloadImage(&wrong, SaveToSlice(&content)), NotCachedError)
+
+	require.ErrorIs(t, c.Get(Artists, nil, &answer), NotCachedError)
+	require.ErrorIs(t, c.DownloadAppend(context.Background(), s.URL+"/other.webp", SaveToSlice(&content)), NotCachedError)
+	_, err = c.GetRandomImageFileURLWithContext(context.Background(), nil)
+	require.ErrorIs(t, err, NotCachedError)
+	require.ErrorIs(t, c.GetRandomImageFileWithContext(context.Background(), nil, SaveToSlice(&content)), NotCachedError)
+	require.Equal(t, int32(3), calls.Load())
+}
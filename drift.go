@@ -0,0 +1,301 @@
+package necos
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DriftKind tells how the response differs from the struct it's decoded into
+type DriftKind string
+
+const (
+	// DriftUnknown is a field present in response, but not in the struct
+	DriftUnknown DriftKind = "unknown"
+	// DriftMissing is a field of the struct absent in response
+	DriftMissing DriftKind = "missing"
+)
+
+// DriftEntry describes a single difference between API responses and structs of the wrapper
+type DriftEntry struct {
+	// Type is the name of the struct, e.g. "Image"
+	Type string
+	// Field is the JSON name of the field
+	Field string
+	Kind  DriftKind
+	// Endpoint is the path template of the call where the difference was seen first
+	Endpoint  string
+	FirstSeen time.Time
+	// Count is the amount of objects with the difference seen so far
+	Count int
+}
+
+func (e DriftEntry) String() string {
+	return fmt.Sprintf("%s field %s.%s (first seen in %s)", e.Kind, e.Type, e.Field, e.Endpoint)
+}
+
+// DriftError is returned by Client in strict mode when response doesn't match the struct it's decoded into,
+// the result is still filled as usual
+type DriftError struct {
+	Endpoint string
+	Entries  []DriftEntry
+}
+
+func (e *DriftError) Error() string {
+	parts := make([]string, len(e.Entries))
+	for i, entry := range e.Entries {
+		parts[i] = string(entry.Kind) + " " + entry.Type + "." + entry.Field
+	}
+	return "necos: response of " + e.Endpoint + " doesn't match schema: " + strings.Join(parts, ", ")
+}
+
+// DriftReport collects differences between API responses and structs of the wrapper, see Client.Drift
+//
+// it's safe for concurrent use
+type DriftReport struct {
+	mu      sync.Mutex
+	entries map[driftKey]*DriftEntry
+}
+
+type driftKey struct {
+	typ, field string
+	kind       DriftKind
+}
+
+// NewDriftReport creates empty DriftReport
+func NewDriftReport() *DriftReport {
+	return &DriftReport{entries: make(map[driftKey]*DriftEntry)}
+}
+
+// Entries returns collected differences sorted by type, kind and field
+func (r *DriftReport) Entries() []DriftEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]DriftEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, *entry)
+	}
+	sortDrift(entries)
+	return entries
+}
+
+// Len returns the amount of collected differences
+func (r *DriftReport) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+// Reset forgets collected differences
+func (r *DriftReport) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clear(r.entries)
+}
+
+// String formats the report with an entry per line
+func (r *DriftReport) String() string {
+	var b strings.Builder
+	for _, entry := range r.Entries() {
+		b.WriteString(entry.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// add merges found differences into the report
+func (r *DriftReport) add(found []DriftEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entries == nil {
+		r.entries = make(map[driftKey]*DriftEntry)
+	}
+	for _, entry := range found {
+		key := driftKey{entry.Type, entry.Field, entry.Kind}
+		if existing, ok := r.entries[key]; ok {
+			existing.Count += entry.Count
+			continue
+		}
+		r.entries[key] = &entry
+	}
+}
+
+// checkDrift compares JSON data with the type of result, adding found differences to Client.Drift
+//
+// in strict mode DriftError is returned if there are any
+func (c *Client) checkDrift(r *request, data []byte, result any) error {
+	if (c.Drift == nil && !c.Strict) || result == nil {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	d := drift{endpoint: r.endpoint.Template, now: time.Now(), found: make(map[driftKey]*DriftEntry)}
+	d.walk(reflect.TypeOf(result), value)
+	if len(d.found) == 0 {
+		return nil
+	}
+
+	found := make([]DriftEntry, 0, len(d.found))
+	for _, entry := range d.found {
+		found = append(found, *entry)
+	}
+	sortDrift(found)
+
+	if c.Drift != nil {
+		c.Drift.add(found)
+	}
+	if c.Strict {
+		return &DriftError{Endpoint: r.endpoint.Template, Entries: found}
+	}
+	return nil
+}
+
+// drift walks decoded JSON value together with the type it's decoded into
+type drift struct {
+	endpoint string
+	now      time.Time
+	found    map[driftKey]*DriftEntry
+}
+
+func (d *drift) walk(t reflect.Type, value any) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if value == nil {
+		return
+	}
+
+	fields := driftFields(t)
+	if t.Kind() != reflect.Struct || len(fields) == 0 {
+		// types decoding themselves (like Rating) are trusted, unless they're structs with fields to check
+		if t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+			return
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		d.walkStruct(t, fields, object)
+	case reflect.Slice, reflect.Array:
+		elements, _ := value.([]any)
+		for _, element := range elements {
+			d.walk(t.Elem(), element)
+		}
+	case reflect.Map:
+		object, _ := value.(map[string]any)
+		for _, v := range object {
+			d.walk(t.Elem(), v)
+		}
+	}
+}
+
+func (d *drift) walkStruct(t reflect.Type, fields []driftField, object map[string]any) {
+	matched := make([]bool, len(fields))
+	for key, v := range object {
+		i := slices.IndexFunc(fields, func(f driftField) bool { return f.name == key })
+		if i < 0 {
+			i = slices.IndexFunc(fields, func(f driftField) bool { return strings.EqualFold(f.name, key) })
+		}
+		if i < 0 {
+			d.add(t, key, DriftUnknown)
+			continue
+		}
+
+		matched[i] = true
+		d.walk(fields[i].typ, v)
+	}
+
+	for i, f := range fields {
+		if !matched[i] {
+			d.add(t, f.name, DriftMissing)
+		}
+	}
+}
+
+func (d *drift) add(t reflect.Type, field string, kind DriftKind) {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+
+	key := driftKey{name, field, kind}
+	if entry, ok := d.found[key]; ok {
+		entry.Count++
+		return
+	}
+	d.found[key] = &DriftEntry{Type: name, Field: field, Kind: kind, Endpoint: d.endpoint, FirstSeen: d.now, Count: 1}
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// driftField is a field of struct as encoding/json sees it
+type driftField struct {
+	name  string
+	typ   reflect.Type
+	index []int
+}
+
+var driftFieldsCache sync.Map
+
+// driftFields returns JSON fields of struct type t
+func driftFields(t reflect.Type) []driftField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := driftFieldsCache.Load(t); ok {
+		return cached.([]driftField)
+	}
+
+	var fields []driftField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for _, embedded := range driftFields(f.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if name == "" {
+			// encoding/json matches names case-insensitively, and API uses lower case
+			name = strings.ToLower(f.Name)
+		}
+		fields = append(fields, driftField{name: name, typ: f.Type, index: f.Index})
+	}
+
+	driftFieldsCache.Store(t, fields)
+	return fields
+}
+
+func sortDrift(entries []DriftEntry) {
+	slices.SortFunc(entries, func(a, b DriftEntry) int {
+		return cmp.Or(strings.Compare(a.Type, b.Type), strings.Compare(string(a.Kind), string(b.Kind)),
+			strings.Compare(a.Field, b.Field))
+	})
+}
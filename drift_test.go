@@ -0,0 +1,110 @@
+package necos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// driftedImage returns JSON of im with field added to it, artist and one more removed
+func driftedImage(t *testing.T, im Image) map[string]any {
+	t.Helper()
+
+	data, err := json.Marshal(im)
+	require.NoError(t, err)
+
+	var object map[string]any
+	require.NoError(t, json.Unmarshal(data, &object))
+	delete(object, "duration")
+	object["new_field"] = 1
+	object["Artist"].(map[string]any)["followers"] = 10
+	return object
+}
+
+func TestDriftReport(t *testing.T) {
+	im := Image{ID: 1, Rating: RatingSafe, Tags: []Tag{{ID: 2}}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any = driftedImage(t, im)
+		switch r.URL.Path {
+		case Images:
+			body = map[string]any{"items": []any{body, body}, "count": 2}
+		case fmt.Sprintf(TagByID, 2):
+			body = im.Tags[0]
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	defer s.Close()
+
+	report := NewDriftReport()
+	c := NewClient(WithDomain(s.URL))
+	c.Drift = report
+
+	// matching response has no drift
+	_, err := c.GetTagByID(2)
+	require.NoError(t, err)
+	require.Zero(t, report.Len())
+
+	got, err := c.GetImageByID(1)
+	require.NoError(t, err)
+	require.Equal(t, im.ID, got.ID)
+
+	_, err = c.GetImages(nil)
+	require.NoError(t, err)
+
+	entries := report.Entries()
+	for i := range entries {
+		require.False(t, entries[i].FirstSeen.IsZero())
+		entries[i].FirstSeen = entries[0].FirstSeen
+	}
+	seen := entries[0].FirstSeen
+	require.Equal(t, []DriftEntry{
+		{Type: "Artist", Field: "followers", Kind: DriftUnknown, Endpoint: ImageByID, FirstSeen: seen, Count: 3},
+		{Type: "Image", Field: "duration", Kind: DriftMissing, Endpoint: ImageByID, FirstSeen: seen, Count: 3},
+		{Type: "Image", Field: "new_field", Kind: DriftUnknown, Endpoint: ImageByID, FirstSeen: seen, Count: 3},
+	}, entries)
+	require.Contains(t, report.String(), "unknown field Image.new_field (first seen in /images/%d)\n")
+
+	// streamed items are checked as well
+	report.Reset()
+	require.NoError(t, c.StreamImages(context.Background(), nil, func(Image) bool { return true }))
+	require.Equal(t, 3, report.Len())
+	require.Equal(t, Images, report.Entries()[0].Endpoint)
+
+	c.Strict = true
+	got, err = c.GetImageByID(1)
+	var driftErr *DriftError
+	require.ErrorAs(t, err, &driftErr)
+	require.Len(t, driftErr.Entries, 3)
+	require.Equal(t, im.ID, got.ID)
+
+	_, err = c.GetTagByID(2)
+	require.NoError(t, err)
+}
+
+func TestDriftSkipsSelfDecodingTypes(t *testing.T) {
+	type withRating struct {
+		Rating Rating
+		Items  []Tag
+		Extra  map[string]json.RawMessage `json:"-"`
+	}
+
+	d := drift{found: make(map[driftKey]*DriftEntry)}
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(`{"rating": {"weird": true}, "items": [{"id": 1, "unknown": 1}]}`), &value))
+	d.walk(reflect.TypeFor[*withRating](), value)
+
+	require.Len(t, d.found, 6)
+	require.Contains(t, d.found, driftKey{"Tag", "unknown", DriftUnknown})
+	require.Contains(t, d.found, driftKey{"Tag", "sub", DriftMissing})
+	require.NotContains(t, d.found, driftKey{"withRating", "extra", DriftMissing})
+
+	// Timestamp is a struct, but it's decoded from number
+	d = drift{found: make(map[driftKey]*DriftEntry)}
+	d.walk(reflect.TypeFor[struct{ CreatedAt Timestamp }](), map[string]any{"createdat": 1711315478.870374})
+	require.Empty(t, d.found)
+}
@@ -2,7 +2,10 @@ package necos
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 )
 
@@ -11,6 +14,7 @@ const (
 	DefaultDomain   = "https://api.nekosapi.com/v3"
 	Images          = "/images"
 	RandomImages    = Images + "/random"
+	RandomImageFile = RandomImages + "/file"
 	ReportImage     = Images + "/report"
 	Tags            = Images + "/tags"
 	TagByID         = Tags + "/%d"
@@ -54,23 +58,26 @@ type Image struct {
 	SampleWidth    int    `json:"sample_width"`
 	SampleHeight   int    `json:"sample_height"`
 	Source         string
-	SourceID       int     `json:"source_id"`
-	Rating         string  `json:"rating"`
-	Verification   string  `json:"verification"`
-	HashMD5        string  `json:"hash_md5"`
-	HashPerceptual string  `json:"hash_perceptual"`
-	ColorDominant  Color   `json:"color_dominant"`
-	ColorPalette   []Color `json:"color_palette"`
-	Duration       int     `json:"duration"`
-	IsOriginal     bool    `json:"is_original"`
-	IsScreenshot   bool    `json:"is_screenshot"`
-	IsFlagged      bool    `json:"is_flagged"`
-	IsAnimated     bool    `json:"is_animated"`
+	SourceID       int          `json:"source_id"`
+	Rating         Rating       `json:"rating"`
+	Verification   Verification `json:"verification"`
+	HashMD5        string       `json:"hash_md5"`
+	HashPerceptual string       `json:"hash_perceptual"`
+	ColorDominant  Color        `json:"color_dominant"`
+	ColorPalette   []Color      `json:"color_palette"`
+	Duration       int          `json:"duration"`
+	IsOriginal     bool         `json:"is_original"`
+	IsScreenshot   bool         `json:"is_screenshot"`
+	IsFlagged      bool         `json:"is_flagged"`
+	IsAnimated     bool         `json:"is_animated"`
 	Artist         Artist
 	Characters     []Character
 	Tags           []Tag
-	CreatedAt      float64 `json:"created_at"`
-	UpdatedAt      float64 `json:"updated_at"`
+	CreatedAt      Timestamp `json:"created_at"`
+	UpdatedAt      Timestamp `json:"updated_at"`
+
+	// Extra keeps fields unknown to the wrapper
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Artist is data type that represents artist data returned by API
@@ -86,6 +93,9 @@ type Artist struct {
 	PolicyRepost bool `json:"policy_repost"`
 	PolicyCredit bool `json:"policy_credit"`
 	PolicyAI     bool `json:"policy_ai"`
+
+	// Extra keeps fields unknown to the wrapper
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Character is data type that represents character data returned by API
@@ -100,11 +110,14 @@ type Character struct {
 	Ages        []int
 	Height      int
 	Weight      int
-	Gender      string
+	Gender      Gender
 	Species     string
 	Birthday    string
 	Nationality string
 	Occupations []string
+
+	// Extra keeps fields unknown to the wrapper
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Tag is data type that represents tag data returned by API
@@ -117,15 +130,15 @@ type Tag struct {
 	Description string
 	Sub         string
 	IsNSFW      bool `json:"is_nsfw"`
+
+	// Extra keeps fields unknown to the wrapper
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Report contains data needed to make POST request to report an image.
 // Should contain id (integer) or url (string)
 type Report = url.Values
 
-// Color is custom data used in parsing of colors
-type Color [3]int
-
 // Request is data needed to make GET request to any of endpoints using URL query
 //
 // since all fields are optional and nothing breaks in the API when providing extra fields
@@ -153,9 +166,7 @@ type Request = url.Values
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetImages(req Request) (MultipleContainer[Image], error) {
-	var ret MultipleContainer[Image]
-	err := c.Get(Images, req, &ret)
-	return ret, err
+	return c.GetImagesWithContext(context.Background(), req)
 }
 
 // GetImagesWithContext is a wrapper for Images endpoint
@@ -163,7 +174,7 @@ func (c *Client) GetImages(req Request) (MultipleContainer[Image], error) {
 // For more info on Request parameters see GetImages
 func (c *Client) GetImagesWithContext(ctx context.Context, req Request) (MultipleContainer[Image], error) {
 	var ret MultipleContainer[Image]
-	err := c.GetWithContext(ctx, Images, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetImages", Images), Images, req, &ret)
 	return ret, err
 }
 
@@ -180,9 +191,7 @@ func (c *Client) GetImagesWithContext(ctx context.Context, req Request) (Multipl
 //   - tag (array of integers) - the tag's ID
 //   - limit (integer) - [1..100], default = 100
 func (c *Client) GetRandomImages(req Request) (MultipleContainer[Image], error) {
-	var ret MultipleContainer[Image]
-	err := c.Get(RandomImages, req, &ret)
-	return ret, err
+	return c.GetRandomImagesWithContext(context.Background(), req)
 }
 
 // GetRandomImagesWithContext is a wrapper for RandomImages endpoint
@@ -190,24 +199,104 @@ func (c *Client) GetRandomImages(req Request) (MultipleContainer[Image], error)
 // For more info on Request parameters see GetRandomImages
 func (c *Client) GetRandomImagesWithContext(ctx context.Context, req Request) (MultipleContainer[Image], error) {
 	var ret MultipleContainer[Image]
-	err := c.GetWithContext(ctx, RandomImages, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetRandomImages", RandomImages), RandomImages, req, &ret)
 	return ret, err
 }
 
+// GetRandomImageFileURL is a wrapper for RandomImageFile endpoint
+//
+// it doesn't follow the redirect returned by API and gives url of the random image file instead
+//
+// Request for GetRandomImageFileURL supports the same parameters as GetRandomImages except limit
+func (c *Client) GetRandomImageFileURL(req Request) (string, error) {
+	return c.GetRandomImageFileURLWithContext(context.Background(), req)
+}
+
+// GetRandomImageFileURLWithContext is a wrapper for RandomImageFile endpoint
+//
+// For more info see GetRandomImageFileURL
+func (c *Client) GetRandomImageFileURLWithContext(ctx context.Context, req Request) (string, error) {
+	ctx = endpointContext(ctx, "GetRandomImageFileURL", RandomImageFile)
+	r := &request{method: http.MethodGet, url: c.buildURL(RandomImageFile, req), path: RandomImageFile, query: req, noRedirect: true,
+		endpoint: endpointOf(ctx, "GetRandomImageFileURL", RandomImageFile)}
+
+	ctx, start := c.begin(ctx, r)
+	location, err := c.randomImageFileURL(ctx, r)
+	c.finish(ctx, r, start, 0, nil, err)
+	return location, err
+}
+
+func (c *Client) randomImageFileURL(ctx context.Context, r *request) (string, error) {
+	// the location is random, so it's never cached
+	if c.Offline {
+		return "", fmt.Errorf("%w: %s %s", NotCachedError, r.method, r.url)
+	}
+
+	response, err := c.send(ctx, r)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	location, err := response.Location()
+	if err != nil {
+		return "", err
+	}
+	return location.String(), nil
+}
+
+// GetRandomImageFile is a wrapper for RandomImageFile endpoint
+//
+// it follows the redirect returned by API and writes the random image file to dst, like DownloadAppend does,
+// so it's limited by MaxImageSize and DownloadLimiter and reported as a download
+//
+// Request for GetRandomImageFile supports the same parameters as GetRandomImages except limit
+func (c *Client) GetRandomImageFile(req Request, dst io.Writer) error {
+	return c.GetRandomImageFileWithContext(context.Background(), req, dst)
+}
+
+// GetRandomImageFileWithContext is a wrapper for RandomImageFile endpoint
+//
+// For more info see GetRandomImageFile
+func (c *Client) GetRandomImageFileWithContext(ctx context.Context, req Request, dst io.Writer) error {
+	ctx = endpointContext(ctx, "GetRandomImageFile", RandomImageFile)
+	r := &request{method: http.MethodGet, url: c.buildURL(RandomImageFile, req), path: RandomImageFile, query: req, download: true,
+		endpoint: endpointOf(ctx, "GetRandomImageFile", RandomImageFile)}
+
+	ctx, start := c.begin(ctx, r)
+	n, err := c.randomImageFile(ctx, r, dst)
+	c.finish(ctx, r, start, int(n), nil, err)
+	return err
+}
+
+func (c *Client) randomImageFile(ctx context.Context, r *request, dst io.Writer) (int64, error) {
+	if c.Offline {
+		return 0, fmt.Errorf("%w: %s %s", NotCachedError, r.method, r.url)
+	}
+
+	response, err := c.send(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	return io.Copy(dst, response.Body)
+}
+
 // PostReport is a wrapper for ReportImage endpoint
 //
 // Report for PostReport supports those parameters:
 //   - id (integer) - probably the id of Image
 //   - url (string) - probably the url of Image
 func (c *Client) PostReport(req Report) error {
-	return c.Post(ReportImage, req, nil)
+	return c.PostReportWithContext(context.Background(), req)
 }
 
 // PostReportWithContext is a wrapper for ReportImage endpoint
 //
 // For more info on Report parameters see PostReport
 func (c *Client) PostReportWithContext(ctx context.Context, req Report) error {
-	return c.PostWithContext(ctx, ReportImage, req, nil)
+	return c.PostWithContext(endpointContext(ctx, "PostReport", ReportImage), ReportImage, req, nil)
 }
 
 // GetTags is a wrapper for Tags endpoint
@@ -218,9 +307,7 @@ func (c *Client) PostReportWithContext(ctx context.Context, req Report) error {
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetTags(req Request) (MultipleContainer[Tag], error) {
-	var ret MultipleContainer[Tag]
-	err := c.Get(Tags, req, &ret)
-	return ret, err
+	return c.GetTagsWithContext(context.Background(), req)
 }
 
 // GetTagsWithContext is a wrapper for Tags endpoint
@@ -228,23 +315,20 @@ func (c *Client) GetTags(req Request) (MultipleContainer[Tag], error) {
 // For more info on Request parameters see GetTags
 func (c *Client) GetTagsWithContext(ctx context.Context, req Request) (MultipleContainer[Tag], error) {
 	var ret MultipleContainer[Tag]
-	err := c.GetWithContext(ctx, Tags, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetTags", Tags), Tags, req, &ret)
 	return ret, err
 }
 
 // GetTagByID is a wrapper for TagByID endpoint
 func (c *Client) GetTagByID(id int) (Tag, error) {
-	var ret Tag
-	path := fmt.Sprintf(TagByID, id)
-	err := c.Get(path, nil, &ret)
-	return ret, err
+	return c.GetTagByIDWithContext(context.Background(), id)
 }
 
 // GetTagByIDWithContext is a wrapper for TagByID endpoint
 func (c *Client) GetTagByIDWithContext(ctx context.Context, id int) (Tag, error) {
 	var ret Tag
 	path := fmt.Sprintf(TagByID, id)
-	err := c.GetWithContext(ctx, path, nil, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetTagByID", TagByID, id), path, nil, &ret)
 	return ret, err
 }
 
@@ -254,49 +338,40 @@ func (c *Client) GetTagByIDWithContext(ctx context.Context, id int) (Tag, error)
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetTagImages(tagID int, req Request) (MultipleContainer[Image], error) {
-	var ret MultipleContainer[Image]
-	path := fmt.Sprintf(TagImages, tagID)
-	err := c.Get(path, req, &ret)
-	return ret, err
+	return c.GetTagImagesWithContext(context.Background(), tagID, req)
 }
 
 // GetTagImagesWithContext is a wrapper for TagImages endpoint
 func (c *Client) GetTagImagesWithContext(ctx context.Context, tagID int, req Request) (MultipleContainer[Image], error) {
 	var ret MultipleContainer[Image]
 	path := fmt.Sprintf(TagImages, tagID)
-	err := c.GetWithContext(ctx, path, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetTagImages", TagImages, tagID), path, req, &ret)
 	return ret, err
 }
 
 // GetImageByID is a wrapper for ImageByID endpoint
 func (c *Client) GetImageByID(id int) (Image, error) {
-	var ret Image
-	path := fmt.Sprintf(ImageByID, id)
-	err := c.Get(path, nil, &ret)
-	return ret, err
+	return c.GetImageByIDWithContext(context.Background(), id)
 }
 
 // GetImageByIDWithContext is a wrapper for ImageByID endpoint
 func (c *Client) GetImageByIDWithContext(ctx context.Context, id int) (Image, error) {
 	var ret Image
 	path := fmt.Sprintf(ImageByID, id)
-	err := c.GetWithContext(ctx, path, nil, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetImageByID", ImageByID, id), path, nil, &ret)
 	return ret, err
 }
 
 // GetImageArtist is a wrapper for ImageArtist endpoint
 func (c *Client) GetImageArtist(id int) (Artist, error) {
-	var ret Artist
-	path := fmt.Sprintf(ImageArtist, id)
-	err := c.Get(path, nil, &ret)
-	return ret, err
+	return c.GetImageArtistWithContext(context.Background(), id)
 }
 
 // GetImageArtistWithContext is a wrapper for ImageArtist endpoint
 func (c *Client) GetImageArtistWithContext(ctx context.Context, id int) (Artist, error) {
 	var ret Artist
 	path := fmt.Sprintf(ImageArtist, id)
-	err := c.GetWithContext(ctx, path, nil, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetImageArtist", ImageArtist, id), path, nil, &ret)
 	return ret, err
 }
 
@@ -307,20 +382,19 @@ func (c *Client) GetImageArtistWithContext(ctx context.Context, id int) (Artist,
 //   - offset (integer) - >= 0, default = 0
 //
 // This method isn't recommended to use since most of the time the server only returns 500 (internal server error)
+// (if you still need it, consider setting Client.Retry)
 func (c *Client) GetImageCharacters(id int, req Request) (MultipleContainer[Character], error) {
-	var ret MultipleContainer[Character]
-	path := fmt.Sprintf(ImageCharacters, id)
-	err := c.Get(path, req, &ret)
-	return ret, err
+	return c.GetImageCharactersWithContext(context.Background(), id, req)
 }
 
 // GetImageCharactersWithContext is a wrapper for ImageCharacters endpoint
 //
 // This method isn't recommended to use since most of the time the server only returns 500 (internal server error)
+// (if you still need it, consider setting Client.Retry)
 func (c *Client) GetImageCharactersWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Character], error) {
 	var ret MultipleContainer[Character]
 	path := fmt.Sprintf(ImageCharacters, id)
-	err := c.GetWithContext(ctx, path, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetImageCharacters", ImageCharacters, id), path, req, &ret)
 	return ret, err
 }
 
@@ -331,20 +405,19 @@ func (c *Client) GetImageCharactersWithContext(ctx context.Context, id int, req
 //   - offset (integer) - >= 0, default = 0
 //
 // This method isn't recommended to use since most of the time the server only returns 500 (internal server error)
+// (if you still need it, consider setting Client.Retry)
 func (c *Client) GetImageTags(id int, req Request) (MultipleContainer[Tag], error) {
-	var ret MultipleContainer[Tag]
-	path := fmt.Sprintf(ImageTags, id)
-	err := c.Get(path, req, &ret)
-	return ret, err
+	return c.GetImageTagsWithContext(context.Background(), id, req)
 }
 
 // GetImageTagsWithContext is a wrapper for ImageTags endpoint
 //
 // This method isn't recommended to use since most of the time the server only returns 500 (internal server error)
+// (if you still need it, consider setting Client.Retry)
 func (c *Client) GetImageTagsWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Tag], error) {
 	var ret MultipleContainer[Tag]
 	path := fmt.Sprintf(ImageTags, id)
-	err := c.GetWithContext(ctx, path, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetImageTags", ImageTags, id), path, req, &ret)
 	return ret, err
 }
 
@@ -358,9 +431,7 @@ func (c *Client) GetImageTagsWithContext(ctx context.Context, id int, req Reques
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetArtists(req Request) (MultipleContainer[Artist], error) {
-	var ret MultipleContainer[Artist]
-	err := c.Get(Artists, req, &ret)
-	return ret, err
+	return c.GetArtistsWithContext(context.Background(), req)
 }
 
 // GetArtistsWithContext is a wrapper for Artists endpoint
@@ -368,23 +439,20 @@ func (c *Client) GetArtists(req Request) (MultipleContainer[Artist], error) {
 // For more info on Request parameters see GetArtists
 func (c *Client) GetArtistsWithContext(ctx context.Context, req Request) (MultipleContainer[Artist], error) {
 	var ret MultipleContainer[Artist]
-	err := c.GetWithContext(ctx, Artists, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetArtists", Artists), Artists, req, &ret)
 	return ret, err
 }
 
 // GetArtistByID is a wrapper for ArtistByID endpoint
 func (c *Client) GetArtistByID(id int) (Artist, error) {
-	var ret Artist
-	path := fmt.Sprintf(ArtistByID, id)
-	err := c.Get(path, nil, &ret)
-	return ret, err
+	return c.GetArtistByIDWithContext(context.Background(), id)
 }
 
 // GetArtistByIDWithContext is a wrapper for ArtistByID endpoint
 func (c *Client) GetArtistByIDWithContext(ctx context.Context, id int) (Artist, error) {
 	var ret Artist
 	path := fmt.Sprintf(ArtistByID, id)
-	err := c.GetWithContext(ctx, path, nil, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetArtistByID", ArtistByID, id), path, nil, &ret)
 	return ret, err
 }
 
@@ -394,17 +462,14 @@ func (c *Client) GetArtistByIDWithContext(ctx context.Context, id int) (Artist,
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetArtistImages(id int, req Request) (MultipleContainer[Image], error) {
-	var ret MultipleContainer[Image]
-	path := fmt.Sprintf(ArtistImages, id)
-	err := c.Get(path, req, &ret)
-	return ret, err
+	return c.GetArtistImagesWithContext(context.Background(), id, req)
 }
 
 // GetArtistImagesWithContext is a wrapper for ArtistImages endpoint
 func (c *Client) GetArtistImagesWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Image], error) {
 	var ret MultipleContainer[Image]
 	path := fmt.Sprintf(ArtistImages, id)
-	err := c.GetWithContext(ctx, path, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetArtistImages", ArtistImages, id), path, req, &ret)
 	return ret, err
 }
 
@@ -420,9 +485,7 @@ func (c *Client) GetArtistImagesWithContext(ctx context.Context, id int, req Req
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetCharacters(req Request) (MultipleContainer[Character], error) {
-	var ret MultipleContainer[Character]
-	err := c.Get(Characters, req, &ret)
-	return ret, err
+	return c.GetCharactersWithContext(context.Background(), req)
 }
 
 // GetCharactersWithContext is a wrapper for Characters endpoint
@@ -430,23 +493,20 @@ func (c *Client) GetCharacters(req Request) (MultipleContainer[Character], error
 // For more info on Request parameters see GetCharacters
 func (c *Client) GetCharactersWithContext(ctx context.Context, req Request) (MultipleContainer[Character], error) {
 	var ret MultipleContainer[Character]
-	err := c.GetWithContext(ctx, Characters, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetCharacters", Characters), Characters, req, &ret)
 	return ret, err
 }
 
 // GetCharacterByID is a wrapper for CharacterByID endpoint
 func (c *Client) GetCharacterByID(id int) (Character, error) {
-	var ret Character
-	path := fmt.Sprintf(CharacterByID, id)
-	err := c.Get(path, nil, &ret)
-	return ret, err
+	return c.GetCharacterByIDWithContext(context.Background(), id)
 }
 
 // GetCharacterByIDWithContext is a wrapper for CharacterByID endpoint
 func (c *Client) GetCharacterByIDWithContext(ctx context.Context, id int) (Character, error) {
 	var ret Character
 	path := fmt.Sprintf(CharacterByID, id)
-	err := c.GetWithContext(ctx, path, nil, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetCharacterByID", CharacterByID, id), path, nil, &ret)
 	return ret, err
 }
 
@@ -456,10 +516,7 @@ func (c *Client) GetCharacterByIDWithContext(ctx context.Context, id int) (Chara
 //   - limit (integer) - [1..100], default = 100
 //   - offset (integer) - >= 0, default = 0
 func (c *Client) GetCharacterImages(id int, req Request) (MultipleContainer[Image], error) {
-	var ret MultipleContainer[Image]
-	path := fmt.Sprintf(CharacterImages, id)
-	err := c.Get(path, req, &ret)
-	return ret, err
+	return c.GetCharacterImagesWithContext(context.Background(), id, req)
 }
 
 // GetCharacterImagesWithContext is a wrapper for CharacterImages endpoint
@@ -468,6 +525,6 @@ func (c *Client) GetCharacterImages(id int, req Request) (MultipleContainer[Imag
 func (c *Client) GetCharacterImagesWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Image], error) {
 	var ret MultipleContainer[Image]
 	path := fmt.Sprintf(CharacterImages, id)
-	err := c.GetWithContext(ctx, path, req, &ret)
+	err := c.GetWithContext(endpointContext(ctx, "GetCharacterImages", CharacterImages, id), path, req, &ret)
 	return ret, err
 }
@@ -1,17 +1,33 @@
 package necos
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 )
 
 // Since I'm lazy to try to mimic the way API should respond to my requests
 // I will simply send requests to API itself
 
+// liveClient returns client of the real API, tests using it are skipped unless NECOS_LIVE_API is set,
+// so go test works without network
+func liveClient(t *testing.T) *Client {
+	t.Helper()
+	if os.Getenv("NECOS_LIVE_API") == "" {
+		t.Skip("set NECOS_LIVE_API to run tests against the real API")
+	}
+	return NewClient()
+}
+
 func TestGetImages(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	_, err := c.GetImages(OneValue())
 	require.NoError(t, err)
@@ -19,7 +35,7 @@ func TestGetImages(t *testing.T) {
 
 func TestGetRandomImages(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	_, err := c.GetRandomImages(OneValue())
 	require.NoError(t, err)
@@ -29,7 +45,7 @@ func TestGetRandomImages(t *testing.T) {
 
 func TestGetTags(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	_, err := c.GetTags(OneValue())
 	require.NoError(t, err)
@@ -37,7 +53,7 @@ func TestGetTags(t *testing.T) {
 
 func TestGetTagByID(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	tags, err := c.GetTags(OneValue())
 	require.NoError(t, err)
@@ -48,7 +64,7 @@ func TestGetTagByID(t *testing.T) {
 
 func TestGetTagImages(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	tags, err := c.GetTags(OneValue())
 	require.NoError(t, err)
@@ -59,7 +75,7 @@ func TestGetTagImages(t *testing.T) {
 
 func TestGetImageByID(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	images, err := c.GetImages(OneValue())
 	require.NoError(t, err)
@@ -70,7 +86,7 @@ func TestGetImageByID(t *testing.T) {
 
 func TestGetImageArtist(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	images, err := c.GetImages(OneValue())
 	require.NoError(t, err)
@@ -81,7 +97,7 @@ func TestGetImageArtist(t *testing.T) {
 
 func TestGetImageCharacters(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	images, err := c.GetImages(OneValue())
 	require.NoError(t, err)
@@ -96,7 +112,7 @@ func TestGetImageCharacters(t *testing.T) {
 
 func TestGetImageTags(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	images, err := c.GetImages(OneValue())
 	require.NoError(t, err)
@@ -111,7 +127,7 @@ func TestGetImageTags(t *testing.T) {
 
 func TestGetArtists(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	_, err := c.GetArtists(OneValue())
 	require.NoError(t, err)
@@ -119,7 +135,7 @@ func TestGetArtists(t *testing.T) {
 
 func TestGetArtistByID(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	artists, err := c.GetArtists(OneValue())
 	require.NoError(t, err)
@@ -130,7 +146,7 @@ func TestGetArtistByID(t *testing.T) {
 
 func TestGetArtistImages(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	artists, err := c.GetArtists(OneValue())
 	require.NoError(t, err)
@@ -141,7 +157,7 @@ func TestGetArtistImages(t *testing.T) {
 
 func TestGetCharacters(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	_, err := c.GetCharacters(OneValue())
 	require.NoError(t, err)
@@ -149,7 +165,7 @@ func TestGetCharacters(t *testing.T) {
 
 func TestGetCharacterByID(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	characters, err := c.GetCharacters(OneValue())
 	require.NoError(t, err)
@@ -160,7 +176,7 @@ func TestGetCharacterByID(t *testing.T) {
 
 func TestGetCharacterImages(t *testing.T) {
 	t.Parallel()
-	c := NewClient()
+	c := liveClient(t)
 
 	characters, err := c.GetCharacters(OneValue())
 	require.NoError(t, err)
@@ -168,3 +184,57 @@ func TestGetCharacterImages(t *testing.T) {
 	_, err = c.GetCharacterImages(characters.Items[0].ID, OneValue())
 	require.NoError(t, err)
 }
+
+func TestGetRandomImageFile(t *testing.T) {
+	t.Parallel()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case RandomImageFile:
+			require.Equal(t, "safe", r.URL.Query().Get("rating"))
+			http.Redirect(w, r, "/files/42.webp", http.StatusFound)
+		case "/files/42.webp":
+			_, _ = w.Write([]byte("image bytes"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	c := Client{Domain: s.URL}
+	var names []string
+	c.Use(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint, _ := EndpointFromContext(req.Context())
+			names = append(names, endpoint.Name)
+			return next.Do(req)
+		})
+	})
+
+	location, err := c.GetRandomImageFileURLWithContext(context.Background(), SafeRequest())
+	require.NoError(t, err)
+	require.Equal(t, s.URL+"/files/42.webp", location)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.GetRandomImageFileWithContext(context.Background(), SafeRequest(), &buf))
+	require.Equal(t, "image bytes", buf.String())
+	// the redirect is followed by the same request, so middleware sees it once
+	require.Equal(t, []string{"GetRandomImageFileURL", "GetRandomImageFile"}, names)
+
+	// the file is a download, so it's limited by MaxImageSize and counted as downloaded bytes
+	metrics := NewMetricsRegistry()
+	c.MaxBodySize, c.MaxImageSize, c.Metrics = 5, -1, metrics
+	buf.Reset()
+	require.NoError(t, c.GetRandomImageFileWithContext(context.Background(), SafeRequest(), &buf))
+	require.Equal(t, "image bytes", buf.String())
+	var text strings.Builder
+	require.NoError(t, metrics.WriteText(&text))
+	require.Contains(t, text.String(), `necos_downloaded_bytes_total{endpoint="download"} 11`)
+
+	c.MaxImageSize = 5
+	require.ErrorIs(t, c.GetRandomImageFileWithContext(context.Background(), SafeRequest(), &buf), ErrResponseTooLarge)
+
+	c.Domain = s.URL + "/nowhere"
+	_, err = c.GetRandomImageFileURLWithContext(context.Background(), nil)
+	require.True(t, IsNotFound(err))
+}
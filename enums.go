@@ -0,0 +1,124 @@
+package necos
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Rating is the age rating of Image
+//
+// values unknown to the wrapper are kept as is, use Known to check for them
+type Rating string
+
+// ratings known to the wrapper from the least to the most explicit
+const (
+	RatingSafe       Rating = "safe"
+	RatingSuggestive Rating = "suggestive"
+	RatingBorderline Rating = "borderline"
+	RatingExplicit   Rating = "explicit"
+)
+
+var ratingOrder = []Rating{RatingSafe, RatingSuggestive, RatingBorderline, RatingExplicit}
+
+func (r Rating) String() string {
+	return string(r)
+}
+
+// Known reports whether r is one of the Rating constants
+func (r Rating) Known() bool {
+	return slices.Contains(ratingOrder, r)
+}
+
+// level returns position of r in ratingOrder, -1 if it's unknown
+func (r Rating) level() int {
+	return slices.Index(ratingOrder, r)
+}
+
+// AtMost reports whether r is not more explicit than other, unknown ratings are never AtMost anything
+func (r Rating) AtMost(other Rating) bool {
+	return r.Known() && other.Known() && r.level() <= other.level()
+}
+
+// AtLeast reports whether r is not less explicit than other, unknown ratings are never AtLeast anything
+func (r Rating) AtLeast(other Rating) bool {
+	return r.Known() && other.Known() && r.level() >= other.level()
+}
+
+// RatingsUpTo returns all known ratings not more explicit than r
+func RatingsUpTo(r Rating) []Rating {
+	if !r.Known() {
+		return nil
+	}
+	return slices.Clone(ratingOrder[:r.level()+1])
+}
+
+func (r *Rating) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, (*string)(r), "Rating")
+}
+
+// Verification is the moderation state of Image
+//
+// values unknown to the wrapper are kept as is, use Known to check for them
+type Verification string
+
+// verification states known to the wrapper
+const (
+	VerificationUnverified Verification = "unverified"
+	VerificationVerified   Verification = "verified"
+	VerificationRejected   Verification = "rejected"
+)
+
+func (v Verification) String() string {
+	return string(v)
+}
+
+// Known reports whether v is one of the Verification constants
+func (v Verification) Known() bool {
+	switch v {
+	case VerificationUnverified, VerificationVerified, VerificationRejected:
+		return true
+	}
+	return false
+}
+
+func (v *Verification) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, (*string)(v), "Verification")
+}
+
+// Gender is the gender of Character
+//
+// values unknown to the wrapper are kept as is, use Known to check for them
+type Gender string
+
+// genders known to the wrapper
+const (
+	GenderMale   Gender = "male"
+	GenderFemale Gender = "female"
+)
+
+func (g Gender) String() string {
+	return string(g)
+}
+
+// Known reports whether g is one of the Gender constants
+func (g Gender) Known() bool {
+	return g == GenderMale || g == GenderFemale
+}
+
+func (g *Gender) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, (*string)(g), "Gender")
+}
+
+// unmarshalEnum decodes string enum, treating null as empty value
+func unmarshalEnum(data []byte, dst *string, name string) error {
+	if string(data) == "null" {
+		*dst = ""
+		return nil
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("can't unmarshal %s: %w", name, err)
+	}
+	return nil
+}
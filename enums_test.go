@@ -0,0 +1,65 @@
+package necos
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRatingOrder(t *testing.T) {
+	require.True(t, RatingSafe.AtMost(RatingSuggestive))
+	require.True(t, RatingSuggestive.AtMost(RatingSuggestive))
+	require.False(t, RatingExplicit.AtMost(RatingBorderline))
+	require.True(t, RatingExplicit.AtLeast(RatingBorderline))
+	require.False(t, RatingSafe.AtLeast(RatingSuggestive))
+
+	unknown := Rating("cursed")
+	require.False(t, unknown.Known())
+	require.False(t, unknown.AtMost(RatingExplicit))
+	require.False(t, unknown.AtLeast(RatingSafe))
+
+	require.Equal(t, []Rating{RatingSafe, RatingSuggestive}, RatingsUpTo(RatingSuggestive))
+	require.Nil(t, RatingsUpTo(unknown))
+}
+
+func TestEnumsUnmarshal(t *testing.T) {
+	var im Image
+	err := json.Unmarshal([]byte(`{"rating": "borderline", "verification": "verified"}`), &im)
+	require.NoError(t, err)
+	require.Equal(t, RatingBorderline, im.Rating)
+	require.Equal(t, VerificationVerified, im.Verification)
+
+	// unknown values are preserved
+	err = json.Unmarshal([]byte(`{"rating": "cursed", "verification": null}`), &im)
+	require.NoError(t, err)
+	require.Equal(t, Rating("cursed"), im.Rating)
+	require.False(t, im.Rating.Known())
+	require.Equal(t, Verification(""), im.Verification)
+
+	var ch Character
+	require.NoError(t, json.Unmarshal([]byte(`{"gender": "female"}`), &ch))
+	require.Equal(t, GenderFemale, ch.Gender)
+	require.True(t, ch.Gender.Known())
+
+	require.Error(t, json.Unmarshal([]byte(`{"gender": 1}`), &ch))
+}
+
+func TestTypedFields(t *testing.T) {
+	req := AddFields(nil,
+		"rating", RatingSafe,
+		"rating", []Rating{RatingSuggestive, RatingBorderline},
+		"tag", []int{1, 2})
+
+	goal := Request{
+		"rating": {"safe", "suggestive", "borderline"},
+		"tag":    {"1", "2"},
+	}
+	require.Equal(t, goal, req)
+
+	SetFields(req, "rating", RatingsUpTo(RatingSuggestive))
+	goal.Set("rating", "safe")
+	goal.Add("rating", "suggestive")
+	require.Equal(t, goal, req)
+
+	require.Equal(t, Request{"rating": {"safe"}}, SafeRequest())
+}
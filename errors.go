@@ -0,0 +1,190 @@
+package necos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBody is the amount of bytes of error response kept in APIError
+const maxErrorBody = 4 << 10
+
+// APIError is returned when API (or image host) responds with unexpected status code
+//
+// it matches BadStatusError with errors.Is, so the old checks keep working
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Path       string
+	Query      url.Values
+	Header     http.Header
+
+	// RetryAfter is parsed Retry-After header, 0 if there was none
+	RetryAfter time.Duration
+
+	// Detail is the "detail" message of API error response (when it's a string)
+	Detail string
+	// ValidationErrors are filled when API rejects the request parameters
+	ValidationErrors []ValidationError
+
+	// Body is the beginning of the response body (up to 4 KiB)
+	Body []byte
+}
+
+// ValidationError is a single entry of "detail" array returned by API on bad parameters
+type ValidationError struct {
+	Loc  []any  `json:"loc"`
+	Msg  string `json:"msg"`
+	Type string `json:"type"`
+}
+
+func (e ValidationError) String() string {
+	loc := make([]string, len(e.Loc))
+	for i, l := range e.Loc {
+		loc[i] = fmt.Sprint(l)
+	}
+	return strings.Join(loc, ".") + ": " + e.Msg
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(BadStatusError.Error())
+	b.WriteString(": ")
+	b.WriteString(e.Status)
+	if e.Method != "" || e.Path != "" {
+		b.WriteString(" (")
+		b.WriteString(e.Method)
+		b.WriteString(" ")
+		b.WriteString(e.Path)
+		b.WriteString(")")
+	}
+
+	if e.Detail != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Detail)
+	}
+	for i, v := range e.ValidationErrors {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// Is makes APIError match BadStatusError
+func (e *APIError) Is(target error) bool {
+	return target == BadStatusError
+}
+
+// ErrResponseTooLarge is matched by every ResponseTooLargeError
+var ErrResponseTooLarge = errors.New("response is too large")
+
+// ResponseTooLargeError is returned when response body exceeds Client.MaxBodySize (API calls)
+// or Client.MaxImageSize (downloads)
+type ResponseTooLargeError struct {
+	Method string
+	Path   string
+	Limit  int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s: %s %s exceeds %d bytes", ErrResponseTooLarge, e.Method, e.Path, e.Limit)
+}
+
+// Is makes ResponseTooLargeError match ErrResponseTooLarge
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// newAPIError builds APIError from response, reading the beginning of its body
+//
+// the body isn't closed, it's the callers responsibility
+func newAPIError(response *http.Response, method, path string, query url.Values) *APIError {
+	e := &APIError{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Method:     method,
+		Path:       path,
+		Query:      query,
+		Header:     response.Header,
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+	}
+
+	e.Body, _ = io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+	e.parseDetail()
+	return e
+}
+
+// parseDetail decodes "detail" field of the body, which can be either a string or list of validation errors
+func (e *APIError) parseDetail() {
+	var body struct {
+		Detail json.RawMessage
+	}
+	if err := json.Unmarshal(e.Body, &body); err != nil || len(body.Detail) == 0 {
+		return
+	}
+
+	if err := json.Unmarshal(body.Detail, &e.Detail); err == nil {
+		return
+	}
+	_ = json.Unmarshal(body.Detail, &e.ValidationErrors)
+}
+
+// parseRetryAfter parses Retry-After header value given either in seconds or as HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// StatusCode returns status code of APIError in err chain, 0 if there's none
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is APIError with 404 status
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsRateLimited reports whether err is APIError with 429 status
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+// IsClientError reports whether err is APIError with 4xx status
+func IsClientError(err error) bool {
+	code := StatusCode(err)
+	return code >= 400 && code < 500
+}
+
+// IsServerError reports whether err is APIError with 5xx status
+func IsServerError(err error) bool {
+	return StatusCode(err) >= 500
+}
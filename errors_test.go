@@ -0,0 +1,89 @@
+package necos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail": "Not Found"}`))
+		case "/invalid":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"detail": [{"loc": ["query", "limit"], "msg": "too big", "type": "value_error"}]}`))
+		case "/limited":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("oops"))
+		}
+	}))
+	defer s.Close()
+
+	c := Client{Domain: s.URL}
+
+	t.Run("not_found", func(t *testing.T) {
+		err := c.Get("/missing", nil, nil)
+		require.ErrorIs(t, err, BadStatusError)
+		require.True(t, IsNotFound(err))
+		require.False(t, IsServerError(err))
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, http.MethodGet, apiErr.Method)
+		require.Equal(t, "/missing", apiErr.Path)
+		require.Equal(t, "Not Found", apiErr.Detail)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		err := c.Get("/invalid", Request{"limit": {"500"}}, nil)
+		require.True(t, IsClientError(err))
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		require.Len(t, apiErr.ValidationErrors, 1)
+		require.Equal(t, "too big", apiErr.ValidationErrors[0].Msg)
+		require.Equal(t, "500", apiErr.Query.Get("limit"))
+		require.Contains(t, err.Error(), "query.limit: too big")
+	})
+
+	t.Run("rate_limited", func(t *testing.T) {
+		err := c.Get("/limited", nil, nil)
+		require.True(t, IsRateLimited(err))
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, 3*time.Second, apiErr.RetryAfter)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		err := c.DownloadAppend(context.Background(), s.URL+"/image.webp", &bytes.Buffer{})
+		require.ErrorIs(t, err, BadStatusError)
+		require.True(t, IsServerError(err))
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, []byte("oops"), apiErr.Body)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	require.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("-1", now))
+	require.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	require.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	require.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Hour).Format(http.TimeFormat), now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
@@ -20,7 +20,7 @@ func main() {
 	// providing default query we avoid need to set it with every Request
 	c.DefaultQuery = necos.AddFields(nil,
 		"limit", 1,
-		"rating", "safe")
+		"rating", necos.RatingSafe)
 
 	// value we will put tag in
 	var tag necos.Tag
@@ -0,0 +1,148 @@
+package necos
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Extra fields keep JSON fields unknown to the wrapper, so they can be accessed before the wrapper is updated
+// and aren't lost when the value is marshalled back
+
+func (im *Image) UnmarshalJSON(data []byte) error {
+	type plain Image
+	return unmarshalExtra(data, (*plain)(im), &im.Extra)
+}
+
+func (im Image) MarshalJSON() ([]byte, error) {
+	type plain Image
+	return marshalExtra(plain(im), im.Extra)
+}
+
+func (a *Artist) UnmarshalJSON(data []byte) error {
+	type plain Artist
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a Artist) MarshalJSON() ([]byte, error) {
+	type plain Artist
+	return marshalExtra(plain(a), a.Extra)
+}
+
+func (ch *Character) UnmarshalJSON(data []byte) error {
+	type plain Character
+	return unmarshalExtra(data, (*plain)(ch), &ch.Extra)
+}
+
+func (ch Character) MarshalJSON() ([]byte, error) {
+	type plain Character
+	return marshalExtra(plain(ch), ch.Extra)
+}
+
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	type plain Tag
+	return unmarshalExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type plain Tag
+	return marshalExtra(plain(t), t.Extra)
+}
+
+// unmarshalExtra decodes data into v (pointer to struct without custom unmarshalling)
+// and puts the fields v doesn't have into extra
+//
+// data is parsed only once: the object is split into raw fields, known ones are decoded into v and the rest is kept,
+// so nested models aren't decoded again and again
+func unmarshalExtra(data []byte, v any, extra *map[string]json.RawMessage) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		// null leaves v as it is
+		return err
+	}
+
+	value := reflect.ValueOf(v).Elem()
+	fields := driftFields(value.Type())
+	for _, f := range fields {
+		key, ok := fieldKey(object, f.name)
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(object[key], value.FieldByIndex(f.index).Addr().Interface()); err != nil {
+			return err
+		}
+		delete(object, key)
+	}
+
+	// keys matching known fields only case-insensitively are ignored by encoding/json if there's exact one
+	maps.DeleteFunc(object, func(key string, _ json.RawMessage) bool {
+		return isKnownField(fields, key)
+	})
+	*extra = nil
+	if len(object) > 0 {
+		*extra = object
+	}
+	return nil
+}
+
+// fieldKey finds key of object for field with given name, preferring exact match like encoding/json does
+func fieldKey(object map[string]json.RawMessage, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+
+	var found []string
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			found = append(found, key)
+		}
+	}
+	if len(found) == 0 {
+		return "", false
+	}
+	// the order of keys is lost, so the choice is at least deterministic
+	return slices.Min(found), true
+}
+
+func isKnownField(fields []driftField, key string) bool {
+	return slices.ContainsFunc(fields, func(f driftField) bool { return strings.EqualFold(f.name, key) })
+}
+
+// marshalExtra encodes v (struct without custom marshalling) appending fields of extra to it
+//
+// extra fields named like fields of v are skipped, so the output never has duplicate keys
+func marshalExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	fields := driftFields(reflect.TypeOf(v))
+	var b bytes.Buffer
+	b.Write(data[:len(data)-1])
+	written := len(data) > 2
+	for _, key := range slices.Sorted(maps.Keys(extra)) {
+		if isKnownField(fields, key) {
+			continue
+		}
+		if written {
+			b.WriteByte(',')
+		}
+		written = true
+
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		if err = json.Compact(&b, extra[key]); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
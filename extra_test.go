@@ -0,0 +1,95 @@
+package necos
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestExtraRoundTrip(t *testing.T) {
+	data := `{
+		"id": 1, "rating": "safe", "new_field": {"a": [1, 2]}, "another": "x",
+		"artist": {"id": 2, "name": "artist", "followers": 10},
+		"characters": [{"id": 3, "name": "character"}],
+		"tags": [{"id": 4, "name": "tag", "weight": 0.5}]
+	}`
+
+	var im Image
+	require.NoError(t, json.Unmarshal([]byte(data), &im))
+	require.Equal(t, 1, im.ID)
+	require.Equal(t, RatingSafe, im.Rating)
+	require.Equal(t, map[string]json.RawMessage{
+		"new_field": json.RawMessage(`{"a": [1, 2]}`),
+		"another":   json.RawMessage(`"x"`),
+	}, im.Extra)
+	require.Equal(t, map[string]json.RawMessage{"followers": json.RawMessage(`10`)}, im.Artist.Extra)
+	require.Nil(t, im.Characters[0].Extra)
+	require.Equal(t, map[string]json.RawMessage{"weight": json.RawMessage(`0.5`)}, im.Tags[0].Extra)
+
+	encoded, err := json.Marshal(im)
+	require.NoError(t, err)
+
+	var object map[string]any
+	require.NoError(t, json.Unmarshal(encoded, &object))
+	require.Equal(t, map[string]any{"a": []any{1.0, 2.0}}, object["new_field"])
+	require.Equal(t, "x", object["another"])
+	require.Equal(t, 10.0, object["Artist"].(map[string]any)["followers"])
+	require.NotContains(t, object, "Extra")
+
+	var decoded Image
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.Equal(t, im.ID, decoded.ID)
+	require.JSONEq(t, `{"a": [1, 2]}`, string(decoded.Extra["new_field"]))
+	require.Len(t, decoded.Extra, 2)
+}
+
+func TestExtraEdgeCases(t *testing.T) {
+	tag := Tag{ID: 1, Name: "tag"}
+	require.NoError(t, json.Unmarshal([]byte(`null`), &tag))
+	require.Equal(t, Tag{ID: 1, Name: "tag"}, tag)
+
+	// decoding replaces previous extra fields
+	tag.Extra = map[string]json.RawMessage{"old": json.RawMessage(`1`)}
+	require.NoError(t, json.Unmarshal([]byte(`{"ID": 2}`), &tag))
+	require.Nil(t, tag.Extra)
+	require.Equal(t, 2, tag.ID)
+
+	require.Error(t, json.Unmarshal([]byte(`{"id": "one"}`), &tag))
+
+	tag.Extra = map[string]json.RawMessage{"broken": json.RawMessage(`{`)}
+	_, err := json.Marshal(tag)
+	require.Error(t, err)
+
+	data, err := marshalExtra(struct{}{}, map[string]json.RawMessage{"b": json.RawMessage(`2`), "a": json.RawMessage(` 1 `)})
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1,"b":2}`, string(data))
+}
+
+func TestExtraKnownKeys(t *testing.T) {
+	// exact key wins over case-insensitive one, which isn't kept as extra either
+	var tag Tag
+	require.NoError(t, json.Unmarshal([]byte(`{"NAME": "upper", "name": "tag", "id": 1}`), &tag))
+	require.Equal(t, "tag", tag.Name)
+	require.Nil(t, tag.Extra)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"Name": "tag", "is_nsfw": true}`), &tag))
+	require.Equal(t, "tag", tag.Name)
+	require.True(t, tag.IsNSFW)
+
+	// extra keys clashing with known fields aren't written twice
+	tag = Tag{ID: 1, Extra: map[string]json.RawMessage{"id": json.RawMessage(`2`), "NAME": json.RawMessage(`"x"`), "new": json.RawMessage(`3`)}}
+	data, err := json.Marshal(tag)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(strings.ToLower(string(data)), `"id"`))
+	require.NotContains(t, string(data), "NAME")
+
+	var decoded Tag
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, 1, decoded.ID)
+	require.Equal(t, map[string]json.RawMessage{"new": json.RawMessage(`3`)}, decoded.Extra)
+
+	data, err = marshalExtra(struct{}{}, map[string]json.RawMessage{"a": json.RawMessage(`1`)})
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, string(data))
+}
@@ -0,0 +1,108 @@
+package necos
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultRedactedParams are query parameters which values are hidden in logs when LogOptions.Redact isn't set
+var DefaultRedactedParams = []string{"token", "access_token", "api_key", "apikey", "key", "secret", "password", "signature"}
+
+// redacted replaces values of secret parameters in logs
+const redacted = "REDACTED"
+
+// LogOptions configure records Client writes to Logger
+type LogOptions struct {
+	// Success is the level of records about successful calls, slog.LevelInfo if nil
+	Success slog.Leveler
+	// Failure is the level of records about failed calls, slog.LevelWarn if nil
+	Failure slog.Leveler
+
+	// Bodies makes response bodies of API calls (up to 4 KiB) added to records when Logger is enabled for slog.LevelDebug
+	Bodies bool
+	// Redact lists query parameters which values are hidden (case-insensitive), DefaultRedactedParams if nil
+	Redact []string
+}
+
+// logCall writes record about the finished call to Logger
+func (c *Client) logCall(ctx context.Context, r *request, latency time.Duration, bytes int, body []byte, err error) {
+	if c.Logger == nil {
+		return
+	}
+
+	level := levelOr(c.LogOptions.Success, slog.LevelInfo)
+	if err != nil {
+		level = levelOr(c.LogOptions.Failure, slog.LevelWarn)
+	}
+	if !c.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	msg := "necos: API call"
+	if r.download {
+		msg = "necos: download"
+	}
+
+	attrs := []slog.Attr{
+		slog.String("endpoint", r.endpoint.Name),
+		slog.String("method", r.method),
+	}
+	if !r.download {
+		attrs = append(attrs, slog.String("template", r.endpoint.Template))
+	}
+	if u, err := url.Parse(r.url); err == nil {
+		u.RawQuery = c.redact(u.Query()).Encode()
+		if r.download {
+			attrs = append(attrs, slog.String("url", u.String()))
+		} else {
+			attrs = append(attrs, slog.String("query", u.RawQuery))
+		}
+	}
+	if r.status != 0 {
+		attrs = append(attrs, slog.Int("status", r.status))
+	}
+	attrs = append(attrs,
+		slog.Duration("latency", latency),
+		slog.Int("bytes", bytes),
+		slog.Int("attempts", r.attempts),
+	)
+	if r.cache != "" {
+		attrs = append(attrs, slog.String("cache", r.cache))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if c.LogOptions.Bodies && body != nil && c.Logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, slog.String("body", string(body[:min(len(body), maxErrorBody)])))
+	}
+
+	c.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// redact hides values of secret parameters in query
+func (c *Client) redact(query url.Values) url.Values {
+	secrets := c.LogOptions.Redact
+	if secrets == nil {
+		secrets = DefaultRedactedParams
+	}
+
+	for k, v := range query {
+		if slices.ContainsFunc(secrets, func(secret string) bool { return strings.EqualFold(secret, k) }) {
+			for i := range v {
+				v[i] = redacted
+			}
+		}
+	}
+	return query
+}
+
+func levelOr(leveler slog.Leveler, def slog.Level) slog.Level {
+	if leveler == nil {
+		return def
+	}
+	return leveler.Level()
+}
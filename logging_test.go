@@ -0,0 +1,109 @@
+package necos
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// logRecords parses records written by slog.JSONHandler
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	buf.Reset()
+	return records
+}
+
+func TestLogging(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/files/1.png":
+			_, _ = w.Write([]byte("image"))
+		case "/images/tags/1":
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	c := NewClient(
+		WithDomain(s.URL),
+		WithDefaultQuery(url.Values{"token": {"hunter2"}}),
+		WithRetry(&RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryableStatuses: []int{http.StatusServiceUnavailable}}),
+		WithCache(NewLRUCache(10), time.Minute),
+	)
+	c.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+	c.LogOptions.Bodies = true
+
+	_, err := c.GetTagByID(1)
+	require.NoError(t, err)
+	_, err = c.GetTagByID(1)
+	require.NoError(t, err)
+
+	records := logRecords(t, &buf)
+	require.Len(t, records, 2)
+	require.NotContains(t, records[0], "body")
+	for k, v := range map[string]any{
+		"level":    "INFO",
+		"msg":      "necos: API call",
+		"endpoint": "GetTagByID",
+		"template": TagByID,
+		"query":    "token=" + redacted,
+		"status":   float64(http.StatusOK),
+		"bytes":    float64(len(`{"id": 1}`)),
+		"attempts": float64(2),
+		"cache":    cacheMiss,
+	} {
+		require.Equal(t, v, records[0][k], k)
+	}
+	require.Equal(t, cacheHit, records[1]["cache"])
+	require.NotContains(t, records[1], "status")
+
+	level.Set(slog.LevelDebug)
+	_, err = c.GetArtistByID(2)
+	require.Error(t, err)
+
+	var content []byte
+	require.NoError(t, c.DownloadImage(&Image{ImageURL: s.URL + "/files/1.png?signature=abc"}, SaveToSlice(&content)))
+
+	records = logRecords(t, &buf)
+	require.Len(t, records, 2)
+	require.Equal(t, "WARN", records[0]["level"])
+	require.Equal(t, float64(http.StatusNotFound), records[0]["status"])
+	require.Contains(t, records[0]["error"], "404")
+
+	require.Equal(t, "necos: download", records[1]["msg"])
+	require.Equal(t, "DownloadImage", records[1]["endpoint"])
+	require.Equal(t, s.URL+"/files/1.png?signature="+redacted, records[1]["url"])
+	require.Equal(t, float64(len("image")), records[1]["bytes"])
+
+	// bodies are dumped at debug level
+	c.Cache = nil
+	_, err = c.GetTagByID(1)
+	require.NoError(t, err)
+	records = logRecords(t, &buf)
+	require.Equal(t, `{"id": 1}`, records[0]["body"])
+}
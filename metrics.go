@@ -0,0 +1,219 @@
+package necos
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// downloadLabel is the endpoint label of image downloads
+const downloadLabel = "download"
+
+// DefaultLatencyBuckets are upper bounds (in seconds) of latency histogram buckets used by MetricsRegistry
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics receives measurements of API calls and image downloads made by Client
+//
+// endpoint is the path template of the call (i.e. TagByID) or "download" for image downloads,
+// implementations must be safe for concurrent use
+type Metrics interface {
+	// Started is called when the call begins
+	Started(endpoint string)
+	// Finished is called when the call ends
+	Finished(endpoint string, m CallMetrics)
+}
+
+// CallMetrics describe the finished call
+type CallMetrics struct {
+	// Status is the status code of the last response, 0 if there was none (i.e. the call was answered from cache)
+	Status   int
+	Latency  time.Duration
+	Bytes    int
+	Attempts int
+	Err      error
+}
+
+// StatusClass returns class of the call status: "2xx", "4xx" etc., "cache" for calls answered from cache
+// and "error" for calls failed without response
+func (m CallMetrics) StatusClass() string {
+	switch {
+	case m.Status != 0:
+		return strconv.Itoa(m.Status/100) + "xx"
+	case m.Err != nil:
+		return "error"
+	default:
+		return "cache"
+	}
+}
+
+// metricsEndpoint returns endpoint label of the request
+func metricsEndpoint(r *request) string {
+	if r.download {
+		return downloadLabel
+	}
+	return r.endpoint.Template
+}
+
+// MetricsRegistry is in-process Metrics implementation, which serves collected metrics
+// in Prometheus text exposition format as http.Handler
+type MetricsRegistry struct {
+	mu      sync.Mutex
+	buckets []float64
+
+	requests map[[2]string]uint64
+	errors   map[[2]string]uint64
+	inFlight map[string]int64
+	latency  map[string]*histogram
+	bytes    map[string]uint64
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+var _ Metrics = (*MetricsRegistry)(nil)
+
+// NewMetricsRegistry creates MetricsRegistry with latency histogram using given buckets (DefaultLatencyBuckets if none)
+func NewMetricsRegistry(buckets ...float64) *MetricsRegistry {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &MetricsRegistry{
+		buckets:  buckets,
+		requests: make(map[[2]string]uint64),
+		errors:   make(map[[2]string]uint64),
+		inFlight: make(map[string]int64),
+		latency:  make(map[string]*histogram),
+		bytes:    make(map[string]uint64),
+	}
+}
+
+// Started increments in-flight gauge of endpoint
+func (m *MetricsRegistry) Started(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[endpoint]++
+}
+
+// Finished records the call
+func (m *MetricsRegistry) Finished(endpoint string, call CallMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[endpoint]--
+
+	key := [2]string{endpoint, call.StatusClass()}
+	m.requests[key]++
+	if call.Err != nil {
+		m.errors[key]++
+	}
+
+	h, ok := m.latency[endpoint]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[endpoint] = h
+	}
+	seconds := call.Latency.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	if endpoint == downloadLabel {
+		m.bytes[endpoint] += uint64(call.Bytes)
+	}
+}
+
+// ServeHTTP writes metrics in text exposition format
+func (m *MetricsRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WriteText(w)
+}
+
+// WriteText writes metrics in text exposition format to w
+func (m *MetricsRegistry) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	writeHeader(&b, "necos_requests_total", "counter", "API calls and downloads made by Client.")
+	for _, key := range sortedKeys(m.requests) {
+		fmt.Fprintf(&b, "necos_requests_total%s %d\n", labels("endpoint", key[0], "class", key[1]), m.requests[key])
+	}
+
+	writeHeader(&b, "necos_request_errors_total", "counter", "API calls and downloads failed with error.")
+	for _, key := range sortedKeys(m.errors) {
+		fmt.Fprintf(&b, "necos_request_errors_total%s %d\n", labels("endpoint", key[0], "class", key[1]), m.errors[key])
+	}
+
+	writeHeader(&b, "necos_requests_in_flight", "gauge", "API calls and downloads in progress.")
+	for _, endpoint := range sortedKeys(m.inFlight) {
+		fmt.Fprintf(&b, "necos_requests_in_flight%s %d\n", labels("endpoint", endpoint), m.inFlight[endpoint])
+	}
+
+	writeHeader(&b, "necos_request_duration_seconds", "histogram", "Latency of API calls and downloads.")
+	for _, endpoint := range sortedKeys(m.latency) {
+		h := m.latency[endpoint]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "necos_request_duration_seconds_bucket%s %d\n",
+				labels("endpoint", endpoint, "le", formatFloat(bound)), h.counts[i])
+		}
+		fmt.Fprintf(&b, "necos_request_duration_seconds_bucket%s %d\n", labels("endpoint", endpoint, "le", "+Inf"), h.count)
+		fmt.Fprintf(&b, "necos_request_duration_seconds_sum%s %s\n", labels("endpoint", endpoint), formatFloat(h.sum))
+		fmt.Fprintf(&b, "necos_request_duration_seconds_count%s %d\n", labels("endpoint", endpoint), h.count)
+	}
+
+	writeHeader(&b, "necos_downloaded_bytes_total", "counter", "Bytes of downloaded images.")
+	for _, endpoint := range sortedKeys(m.bytes) {
+		fmt.Fprintf(&b, "necos_downloaded_bytes_total%s %d\n", labels("endpoint", endpoint), m.bytes[endpoint])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelEscaper escapes label values of text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats label set out of key-value pairs
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func writeHeader(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sortedKeys[K string | [2]string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+	return keys
+}
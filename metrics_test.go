@@ -0,0 +1,88 @@
+package necos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallMetricsStatusClass(t *testing.T) {
+	require.Equal(t, "2xx", CallMetrics{Status: http.StatusOK}.StatusClass())
+	require.Equal(t, "3xx", CallMetrics{Status: http.StatusNotModified}.StatusClass())
+	require.Equal(t, "5xx", CallMetrics{Status: http.StatusBadGateway, Err: errors.New("bad")}.StatusClass())
+	require.Equal(t, "error", CallMetrics{Err: context.Canceled}.StatusClass())
+	require.Equal(t, "cache", CallMetrics{}.StatusClass())
+}
+
+func TestMetricsRegistry(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/1.png":
+			_, _ = w.Write([]byte("image"))
+		case "/images/tags/1", "/images/tags/2":
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		case ReportImage:
+			// report answers with empty body
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	m := NewMetricsRegistry(0.5, 0.1)
+	c := NewClient(WithDomain(s.URL), WithMetrics(m))
+
+	_, err := c.GetTagByID(1)
+	require.NoError(t, err)
+	// direct calls are labelled by template of the path too
+	var tag Tag
+	require.NoError(t, c.Get(fmt.Sprintf(TagByID, 2), nil, &tag))
+	_, err = c.GetArtistByID(3)
+	require.True(t, IsNotFound(err))
+	// calls without result are measured even though their response is empty
+	require.NoError(t, c.PostReport(AddFields(nil, "id", 1)))
+
+	var content []byte
+	require.NoError(t, c.DownloadImage(&Image{ImageURL: s.URL + "/files/1.png"}, SaveToSlice(&content)))
+
+	// the latency of a second long call goes only to +Inf bucket
+	m.Started("slow")
+	m.Finished("slow", CallMetrics{Status: http.StatusOK, Latency: time.Second})
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+
+	text := w.Body.String()
+	for _, line := range []string{
+		"# TYPE necos_requests_total counter",
+		`necos_requests_total{endpoint="/images/tags/%d",class="2xx"} 2`,
+		`necos_requests_total{endpoint="/artists/%d",class="4xx"} 1`,
+		`necos_requests_total{endpoint="download",class="2xx"} 1`,
+		`necos_request_errors_total{endpoint="/artists/%d",class="4xx"} 1`,
+		`necos_requests_total{endpoint="/images/report",class="2xx"} 1`,
+		`necos_requests_in_flight{endpoint="/images/tags/%d"} 0`,
+		"# TYPE necos_request_duration_seconds histogram",
+		`necos_request_duration_seconds_bucket{endpoint="/images/tags/%d",le="+Inf"} 2`,
+		`necos_request_duration_seconds_count{endpoint="/images/tags/%d"} 2`,
+		`necos_request_duration_seconds_bucket{endpoint="slow",le="0.1"} 0`,
+		`necos_request_duration_seconds_bucket{endpoint="slow",le="0.5"} 0`,
+		`necos_request_duration_seconds_bucket{endpoint="slow",le="+Inf"} 1`,
+		`necos_downloaded_bytes_total{endpoint="download"} 5`,
+	} {
+		require.Contains(t, text, line+"\n")
+	}
+	require.NotContains(t, text, "/images/tags/1")
+	require.NotContains(t, text, "/images/tags/2")
+	require.Less(t, strings.Index(text, `le="0.1"`), strings.Index(text, `le="0.5"`))
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	require.Equal(t, `{endpoint="a\"b\\c\nd",class="2xx"}`, labels("endpoint", "a\"b\\c\nd", "class", "2xx"))
+}
@@ -0,0 +1,89 @@
+package necos
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Doer makes HTTP requests, *http.Client is the one used by Client in the end
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is a function implementing Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps Doer to do something before or after requests, e.g. add headers, log or inject faults
+//
+// it's called for every request Client sends (every retry attempt of API calls and image downloads),
+// but not for calls answered from cache. Endpoint of the request can be found with EndpointFromContext(req.Context())
+type Middleware func(next Doer) Doer
+
+// Use appends middlewares to Client, the first one is the outermost
+func (c *Client) Use(middlewares ...Middleware) {
+	c.Middleware = append(c.Middleware, middlewares...)
+}
+
+// WithMiddleware appends middlewares to Client, see Client.Use
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.Use(middlewares...)
+	}
+}
+
+// chain wraps doer into Client middlewares
+func (c *Client) chain(doer Doer) Doer {
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		doer = c.Middleware[i](doer)
+	}
+	return doer
+}
+
+// Endpoint describes the logical API call request is made for
+type Endpoint struct {
+	// Name is the name of Client method, e.g. "GetTagImages" (the same for its WithContext version)
+	Name string
+	// Template is the path template, e.g. TagImages, it's empty for downloads
+	//
+	// for calls made with CallAPI, Get and Post it's made from the path, with numbers replaced by %d
+	Template string
+	// Params are the values substituted into Template
+	Params []any
+	// Query is the query of the call (without DefaultQuery)
+	Query url.Values
+	// Download is set for image downloads
+	Download bool
+}
+
+type endpointKey struct{}
+
+// ContextWithEndpoint returns context carrying endpoint,
+// use it to describe the calls made with CallAPIWithContext and Download methods
+func ContextWithEndpoint(ctx context.Context, endpoint Endpoint) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// EndpointFromContext returns endpoint the request with given context is made for
+func EndpointFromContext(ctx context.Context) (Endpoint, bool) {
+	endpoint, ok := ctx.Value(endpointKey{}).(Endpoint)
+	return endpoint, ok
+}
+
+// endpointContext attaches endpoint with given name, template and params to ctx
+func endpointContext(ctx context.Context, name, template string, params ...any) context.Context {
+	return ContextWithEndpoint(ctx, Endpoint{Name: name, Template: template, Params: params})
+}
+
+// endpointOf returns endpoint attached to ctx, if there's none the endpoint with given name and template is used
+func endpointOf(ctx context.Context, name, template string) Endpoint {
+	if endpoint, ok := EndpointFromContext(ctx); ok {
+		return endpoint
+	}
+	return Endpoint{Name: name, Template: template}
+}
@@ -0,0 +1,109 @@
+package necos
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareEndpoint(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get("Authorization"))
+		if strings.HasPrefix(r.URL.Path, "/files/") {
+			_, _ = w.Write([]byte("image"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [], "count": 0}`))
+	}))
+	defer s.Close()
+
+	var mu sync.Mutex
+	var endpoints []Endpoint
+	var order []string
+
+	record := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint, ok := EndpointFromContext(req.Context())
+			require.True(t, ok)
+
+			mu.Lock()
+			endpoints = append(endpoints, endpoint)
+			order = append(order, "record")
+			mu.Unlock()
+			return next.Do(req)
+		})
+	}
+	auth := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			order = append(order, "auth")
+			mu.Unlock()
+
+			req.Header.Set("Authorization", "secret")
+			return next.Do(req)
+		})
+	}
+
+	c := NewClient(WithDomain(s.URL), WithMiddleware(record))
+	c.Use(auth)
+
+	_, err := c.GetTagImages(7, OneValue())
+	require.NoError(t, err)
+	_, err = c.GetTagsWithContext(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Get("/custom", nil, &json.RawMessage{}))
+
+	var content []byte
+	require.NoError(t, c.DownloadImage(&Image{ImageURL: s.URL + "/files/1.png"}, SaveToSlice(&content)))
+	require.NoError(t, c.Download(context.Background(), s.URL+"/files/2.png", SaveToSlice(&content)))
+
+	require.Equal(t, []Endpoint{
+		{Name: "GetTagImages", Template: TagImages, Params: []any{7}, Query: OneValue()},
+		{Name: "GetTags", Template: Tags},
+		{Template: "/custom"},
+		{Name: "DownloadImage", Download: true},
+		{Name: "Download", Download: true},
+	}, endpoints)
+	require.Equal(t, []string{"record", "auth"}, order[:2])
+}
+
+func TestMiddlewareFaultInjection(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer s.Close()
+
+	var attempts int
+	failFirst := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts == 1 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Status:     "503 Service Unavailable",
+					Header:     make(http.Header),
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			}
+			return next.Do(req)
+		})
+	}
+
+	c := NewClient(WithDomain(s.URL), WithMiddleware(failFirst),
+		WithRetry(&RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryableStatuses: []int{http.StatusServiceUnavailable}}))
+
+	tag, err := c.GetTagByID(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, tag.ID)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, 1, calls)
+}
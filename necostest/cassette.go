@@ -0,0 +1,327 @@
+package necostest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rinnothing/go-necos"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// NoInteractionError is returned by Recorder when there's no recorded interaction for the request
+var NoInteractionError = errors.New("no recorded interaction for request")
+
+// Mode sets how Recorder treats requests
+type Mode int
+
+const (
+	// ModeReplay answers with recorded interactions (in any order and any number of times),
+	// unmatched requests fail with NoInteractionError
+	ModeReplay Mode = iota
+	// ModeRecord always makes real requests and records them, replacing the previous cassette
+	ModeRecord
+	// ModeRecordIfMissing replays recorded interactions and records the ones that are missing
+	ModeRecordIfMissing
+	// ModeStrict replays recorded interactions exactly in the recorded order, each only once
+	ModeStrict
+)
+
+// DefaultScrubHeaders are headers that are never written to cassette
+var DefaultScrubHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Proxy-Authorization"}
+
+// scrubbed replaces values of ScrubQuery parameters in cassette
+const scrubbed = "REDACTED"
+
+// Interaction is recorded request and response pair
+type Interaction struct {
+	Request  RecordedRequest
+	Response RecordedResponse
+}
+
+// RecordedRequest is the request part of Interaction
+type RecordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header `json:",omitempty"`
+}
+
+// RecordedResponse is the response part of Interaction
+//
+// Body is kept as is when it's valid UTF-8, otherwise it's encoded with base64 (and BodyEncoding is "base64")
+type RecordedResponse struct {
+	StatusCode   int
+	Header       http.Header `json:",omitempty"`
+	Body         string
+	BodyEncoding string `json:",omitempty"`
+}
+
+// Recorder is http.RoundTripper recording interactions to cassette file and replaying them
+//
+// requests are matched by method, path and normalized query (host is ignored),
+// so cassette recorded against real API can be replayed with any Domain.
+// Values of ScrubQuery parameters are matched after scrubbing, so secrets may differ between recording and replay
+type Recorder struct {
+	path string
+	mode Mode
+
+	// Transport makes real requests, http.DefaultTransport is used if it's nil
+	Transport http.RoundTripper
+	// ScrubHeaders are removed from recorded requests and responses
+	ScrubHeaders []string
+	// ScrubQuery are query parameters (case-insensitive) which values are replaced in recorded urls,
+	// user info of urls is always removed
+	ScrubQuery []string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	next         int
+	changed      bool
+}
+
+// NewRecorder creates Recorder for cassette at path, loading it if it exists
+//
+// cassette must exist for ModeReplay and ModeStrict
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		path:         path,
+		mode:         mode,
+		ScrubHeaders: DefaultScrubHeaders,
+		ScrubQuery:   necos.DefaultRedactedParams,
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeRecordIfMissing {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("can't read cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// Install makes Client send requests through Recorder, the previous Transport of Client is used for real requests
+func (r *Recorder) Install(c *necos.Client) {
+	if r.Transport == nil {
+		r.Transport = c.Transport
+	}
+	c.Transport = r
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode != ModeRecord {
+		if interaction, ok := r.match(req); ok {
+			return interaction.Response.toResponse(req), nil
+		}
+		if r.mode != ModeRecordIfMissing {
+			return nil, fmt.Errorf("%w: %s %s", NoInteractionError, req.Method, req.URL)
+		}
+	}
+
+	return r.record(req)
+}
+
+// match finds recorded interaction for request and marks it used
+func (r *Recorder) match(req *http.Request) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := matchKey(req.Method, r.scrubURL(req.URL))
+	if r.mode == ModeStrict {
+		if r.next >= len(r.interactions) || r.key(r.interactions[r.next]) != key {
+			return Interaction{}, false
+		}
+		r.used[r.next] = true
+		r.next++
+		return r.interactions[r.next-1], true
+	}
+
+	for i, interaction := range r.interactions {
+		if r.key(interaction) == key {
+			r.used[i] = true
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
+
+// record makes the real request and remembers it
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.scrubURL(req.URL).String(),
+			Header: r.scrub(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     r.scrub(response.Header),
+		},
+	}
+	if utf8.Valid(body) {
+		interaction.Response.Body = string(body)
+	} else {
+		interaction.Response.Body = base64.StdEncoding.EncodeToString(body)
+		interaction.Response.BodyEncoding = "base64"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+	r.used = append(r.used, true)
+	r.changed = true
+	return response, nil
+}
+
+// scrub returns copy of header without ScrubHeaders
+func (r *Recorder) scrub(header http.Header) http.Header {
+	res := header.Clone()
+	for _, name := range r.ScrubHeaders {
+		res.Del(name)
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+// scrubURL returns copy of u without user info and with values of ScrubQuery parameters replaced
+func (r *Recorder) scrubURL(u *url.URL) *url.URL {
+	res := *u
+	res.User = nil
+
+	query := res.Query()
+	changed := false
+	for k, v := range query {
+		if slices.ContainsFunc(r.ScrubQuery, func(name string) bool { return strings.EqualFold(name, k) }) {
+			for i := range v {
+				v[i] = scrubbed
+			}
+			changed = true
+		}
+	}
+	if changed {
+		res.RawQuery = query.Encode()
+	}
+	return &res
+}
+
+// Save writes cassette file if anything was recorded
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := r.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, r.path); err != nil {
+		return err
+	}
+
+	r.changed = false
+	return nil
+}
+
+// Unused returns recorded interactions that weren't replayed or recorded,
+// handy to check that test made all the expected requests
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			res = append(res, interaction)
+		}
+	}
+	return res
+}
+
+// key makes match key of recorded interaction, cassettes recorded before scrubbing of query are matched too
+func (r *Recorder) key(i Interaction) string {
+	u, err := url.Parse(i.Request.URL)
+	if err != nil {
+		return i.Request.Method + " " + i.Request.URL
+	}
+	return matchKey(i.Request.Method, r.scrubURL(u))
+}
+
+// matchKey makes key used to match requests out of method, path and normalized query
+func matchKey(method string, u *url.URL) string {
+	query := u.Query()
+	for _, values := range query {
+		slices.Sort(values)
+	}
+	return strings.ToUpper(method) + " " + u.Path + "?" + query.Encode()
+}
+
+func (rr *RecordedResponse) toResponse(req *http.Request) *http.Response {
+	body := []byte(rr.Body)
+	if rr.BodyEncoding == "base64" {
+		body, _ = base64.StdEncoding.DecodeString(rr.Body)
+	}
+
+	header := rr.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
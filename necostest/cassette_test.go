@@ -0,0 +1,167 @@
+package necostest
+
+import (
+	"context"
+	"github.com/rinnothing/go-necos"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// authTransport adds Authorization header to requests
+type authTransport struct {
+	next http.RoundTripper
+}
+
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "secret")
+	return t.next.RoundTrip(req)
+}
+
+// record makes a few calls to fake server through Recorder in ModeRecord and saves the cassette
+func record(t *testing.T, path string) (necos.Image, []byte) {
+	t.Helper()
+
+	s := NewServer(5)
+	defer s.Close()
+
+	rec, err := NewRecorder(path, ModeRecord)
+	require.NoError(t, err)
+
+	c := s.Client()
+	rec.Install(c)
+	c.Transport = authTransport{rec}
+
+	images, err := c.GetImages(necos.OneValue())
+	require.NoError(t, err)
+	im := images.Items[0]
+
+	var content []byte
+	require.NoError(t, c.DownloadImage(&im, necos.SaveToSlice(&content)))
+
+	require.NoError(t, rec.Save())
+	return im, content
+}
+
+func TestRecorderReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	im, content := record(t, path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.False(t, strings.Contains(string(data), "secret"))
+
+	rec, err := NewRecorder(path, ModeReplay)
+	require.NoError(t, err)
+
+	// server is closed, everything comes from cassette
+	c := necos.NewClient()
+	c.Domain = "http://nekos.invalid"
+	rec.Install(c)
+
+	// query order doesn't matter
+	images, err := c.GetImages(necos.Request{"limit": {"1"}})
+	require.NoError(t, err)
+	require.Equal(t, im, images.Items[0])
+
+	var replayed []byte
+	require.NoError(t, c.DownloadImage(&im, necos.SaveToSlice(&replayed)))
+	require.Equal(t, content, replayed)
+	require.Empty(t, rec.Unused())
+
+	_, err = c.GetTags(nil)
+	require.ErrorIs(t, err, NoInteractionError)
+}
+
+func TestRecorderStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	im, _ := record(t, path)
+
+	rec, err := NewRecorder(path, ModeStrict)
+	require.NoError(t, err)
+
+	c := necos.NewClient()
+	c.Domain = "http://nekos.invalid"
+	rec.Install(c)
+
+	// out of order request fails
+	var content []byte
+	require.ErrorIs(t, c.DownloadImage(&im, necos.SaveToSlice(&content)), NoInteractionError)
+
+	_, err = c.GetImages(necos.OneValue())
+	require.NoError(t, err)
+	require.Len(t, rec.Unused(), 1)
+
+	// interactions aren't reused
+	_, err = c.GetImages(necos.OneValue())
+	require.ErrorIs(t, err, NoInteractionError)
+}
+
+func TestRecorderRecordIfMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := NewServer(6)
+	defer s.Close()
+
+	for i := 0; i < 2; i++ {
+		rec, err := NewRecorder(path, ModeRecordIfMissing)
+		require.NoError(t, err)
+
+		c := s.Client()
+		rec.Install(c)
+
+		_, err = c.GetTagByID(1)
+		require.NoError(t, err)
+		_, err = c.GetImagesWithContext(context.Background(), necos.OneValue())
+		require.NoError(t, err)
+		require.NoError(t, rec.Save())
+	}
+
+	// the second round was replayed
+	require.Len(t, s.Requests(), 2)
+
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	require.Error(t, err)
+}
+
+func TestRecorderScrubQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := NewServer(7)
+	defer s.Close()
+
+	rec, err := NewRecorder(path, ModeRecord)
+	require.NoError(t, err)
+
+	c := s.Client()
+	c.Domain = strings.Replace(c.Domain, "://", "://user:hunter1@", 1)
+	c.DefaultQuery = url.Values{"Token": {"hunter2"}}
+	rec.Install(c)
+
+	_, err = c.GetTags(necos.OneValue())
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "hunter")
+	require.Contains(t, string(data), "Token=REDACTED")
+
+	// replay matches whatever the secret is
+	rec, err = NewRecorder(path, ModeReplay)
+	require.NoError(t, err)
+
+	c = necos.NewClient(necos.WithDefaultQuery(url.Values{"Token": {"other"}}))
+	c.Domain = "http://nekos.invalid"
+	rec.Install(c)
+
+	_, err = c.GetTags(necos.OneValue())
+	require.NoError(t, err)
+	_, err = c.GetTags(necos.Request{"Token": {"other"}, "limit": {"2"}})
+	require.ErrorIs(t, err, NoInteractionError)
+}
@@ -0,0 +1,196 @@
+package necostest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/rinnothing/go-necos"
+	"image"
+	"image/png"
+	"math/rand/v2"
+	"time"
+)
+
+// Dataset is the data served by Server
+//
+// image urls in it are relative ("/files/1.png"), Server makes them absolute when responding
+type Dataset struct {
+	Images     []necos.Image
+	Tags       []necos.Tag
+	Artists    []necos.Artist
+	Characters []necos.Character
+
+	// Files are contents of image files by their path
+	Files map[string][]byte
+}
+
+// Sizes sets how many entities NewDataset generates
+type Sizes struct {
+	Images     int
+	Tags       int
+	Artists    int
+	Characters int
+}
+
+// DefaultSizes are used by NewServer
+var DefaultSizes = Sizes{Images: 250, Tags: 30, Artists: 12, Characters: 20}
+
+var (
+	ratings = []necos.Rating{
+		necos.RatingSafe, necos.RatingSafe, necos.RatingSuggestive, necos.RatingBorderline, necos.RatingExplicit,
+	}
+	verifications = []necos.Verification{necos.VerificationVerified, necos.VerificationUnverified}
+	genders       = []necos.Gender{necos.GenderFemale, necos.GenderMale}
+	words         = []string{
+		"cat", "sky", "maid", "school", "sword", "night", "rain", "flower", "city", "sea",
+		"forest", "smile", "ribbon", "star", "snow", "book", "tea", "train", "moon", "fox",
+	}
+	species      = []string{"human", "catgirl", "fox", "elf", "demon"}
+	nationality  = []string{"japanese", "british", "french", "unknown"}
+	occupations  = []string{"student", "maid", "knight", "idol", "witch"}
+	fileBaseTime = int64(1.7e9)
+)
+
+// NewDataset generates Dataset of given sizes, the same seed always gives the same Dataset
+func NewDataset(seed uint64, sizes Sizes) *Dataset {
+	r := rand.New(rand.NewPCG(seed, seed))
+	d := &Dataset{Files: make(map[string][]byte)}
+
+	for i := 1; i <= sizes.Tags; i++ {
+		name := words[(i-1)%len(words)]
+		if i > len(words) {
+			name += fmt.Sprint(i)
+		}
+		d.Tags = append(d.Tags, necos.Tag{
+			ID:          i,
+			IDv2:        fmt.Sprintf("tag-%d", i),
+			Name:        name,
+			Description: "Images with " + name,
+			Sub:         words[r.IntN(len(words))],
+			IsNSFW:      r.IntN(5) == 0,
+		})
+	}
+
+	for i := 1; i <= sizes.Artists; i++ {
+		d.Artists = append(d.Artists, necos.Artist{
+			ID:           i,
+			IDv2:         fmt.Sprintf("artist-%d", i),
+			Name:         fmt.Sprintf("Artist %d", i),
+			Aliases:      []string{fmt.Sprintf("a%d", i)},
+			ImageURL:     fmt.Sprintf("/files/artist-%d.png", i),
+			Links:        []string{fmt.Sprintf("https://example.com/artist/%d", i)},
+			PolicyRepost: r.IntN(2) == 0,
+			PolicyCredit: r.IntN(2) == 0,
+			PolicyAI:     r.IntN(4) == 0,
+		})
+	}
+
+	for i := 1; i <= sizes.Characters; i++ {
+		d.Characters = append(d.Characters, necos.Character{
+			ID:          i,
+			IDv2:        fmt.Sprintf("character-%d", i),
+			Name:        fmt.Sprintf("Character %d", i),
+			Aliases:     []string{fmt.Sprintf("c%d", i)},
+			Description: "Character who likes " + words[r.IntN(len(words))],
+			Ages:        []int{14 + r.IntN(20)},
+			Height:      140 + r.IntN(50),
+			Weight:      40 + r.IntN(40),
+			Gender:      genders[r.IntN(len(genders))],
+			Species:     species[r.IntN(len(species))],
+			Birthday:    fmt.Sprintf("%02d-%02d", 1+r.IntN(12), 1+r.IntN(28)),
+			Nationality: nationality[r.IntN(len(nationality))],
+			Occupations: []string{occupations[r.IntN(len(occupations))]},
+		})
+	}
+
+	for i := 1; i <= sizes.Images; i++ {
+		d.Images = append(d.Images, d.newImage(r, i))
+	}
+	return d
+}
+
+// newImage generates Image with given id and its files
+func (d *Dataset) newImage(r *rand.Rand, id int) necos.Image {
+	dominant := randomColor(r)
+	palette := make([]necos.Color, 5)
+	palette[0] = dominant
+	for i := 1; i < len(palette); i++ {
+		palette[i] = randomColor(r)
+	}
+
+	imagePath := fmt.Sprintf("/files/%d.png", id)
+	samplePath := fmt.Sprintf("/files/%d.sample.png", id)
+	content := makePNG(dominant, 16)
+	sample := makePNG(dominant, 8)
+	d.Files[imagePath] = content
+	d.Files[samplePath] = sample
+
+	hash := md5.Sum(content)
+	// API gives timestamps with microsecond precision
+	created := time.Unix(fileBaseTime+int64(id)*3600, int64(r.IntN(1e6))*int64(time.Microsecond))
+
+	im := necos.Image{
+		ID:             id,
+		IDv2:           fmt.Sprintf("image-%d", id),
+		ImageURL:       imagePath,
+		SampleURL:      samplePath,
+		ImageSize:      len(content),
+		ImageWidth:     16,
+		ImageHeight:    16,
+		SampleSize:     len(sample),
+		SampleWidth:    8,
+		SampleHeight:   8,
+		Source:         fmt.Sprintf("https://example.com/source/%d", id),
+		SourceID:       id,
+		Rating:         ratings[r.IntN(len(ratings))],
+		Verification:   verifications[r.IntN(len(verifications))],
+		HashMD5:        hex.EncodeToString(hash[:]),
+		HashPerceptual: fmt.Sprintf("%016x", r.Uint64()),
+		ColorDominant:  dominant,
+		ColorPalette:   palette,
+		IsOriginal:     r.IntN(2) == 0,
+		IsScreenshot:   r.IntN(6) == 0,
+		IsFlagged:      r.IntN(10) == 0,
+		IsAnimated:     r.IntN(8) == 0,
+		CreatedAt:      necos.NewTimestamp(created),
+		UpdatedAt:      necos.NewTimestamp(created.Add(time.Duration(r.IntN(3600e6)) * time.Microsecond)),
+	}
+
+	if len(d.Artists) > 0 {
+		im.Artist = d.Artists[r.IntN(len(d.Artists))]
+	}
+	for _, i := range pick(r, len(d.Characters), 2) {
+		im.Characters = append(im.Characters, d.Characters[i])
+	}
+	for _, i := range pick(r, len(d.Tags), 3) {
+		im.Tags = append(im.Tags, d.Tags[i])
+	}
+	return im
+}
+
+// pick returns up to n distinct random indexes in [0..size)
+func pick(r *rand.Rand, size, n int) []int {
+	if size == 0 {
+		return nil
+	}
+	return r.Perm(size)[:min(size, 1+r.IntN(n))]
+}
+
+func randomColor(r *rand.Rand) necos.Color {
+	return necos.Color{r.IntN(256), r.IntN(256), r.IntN(256)}
+}
+
+// makePNG makes square PNG of given size filled with c
+func makePNG(c necos.Color, size int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
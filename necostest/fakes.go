@@ -0,0 +1,769 @@
+// Code generated by genfakes from services.go; DO NOT EDIT.
+
+package necostest
+
+import (
+	"context"
+	"github.com/rinnothing/go-necos"
+	"io"
+	"sync"
+)
+
+// FakeImageService is configurable fake of necos.ImageService
+//
+// every method calls the function in its Func field if it's set, methods without context
+// fall back to their WithContext versions, otherwise zero values are returned.
+// Only the method called from outside is counted, the fallbacks are not
+type FakeImageService struct {
+	GetImagesFunc                        func(req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetImagesWithContextFunc             func(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetRandomImagesFunc                  func(req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetRandomImagesWithContextFunc       func(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetRandomImageFileURLFunc            func(req necos.Request) (string, error)
+	GetRandomImageFileURLWithContextFunc func(ctx context.Context, req necos.Request) (string, error)
+	GetRandomImageFileFunc               func(req necos.Request, dst io.Writer) error
+	GetRandomImageFileWithContextFunc    func(ctx context.Context, req necos.Request, dst io.Writer) error
+	GetImageByIDFunc                     func(id int) (necos.Image, error)
+	GetImageByIDWithContextFunc          func(ctx context.Context, id int) (necos.Image, error)
+	GetImageArtistFunc                   func(id int) (necos.Artist, error)
+	GetImageArtistWithContextFunc        func(ctx context.Context, id int) (necos.Artist, error)
+	GetImageCharactersFunc               func(id int, req necos.Request) (necos.MultipleContainer[necos.Character], error)
+	GetImageCharactersWithContextFunc    func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Character], error)
+	GetImageTagsFunc                     func(id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error)
+	GetImageTagsWithContextFunc          func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error)
+	PostReportFunc                       func(req necos.Report) error
+	PostReportWithContextFunc            func(ctx context.Context, req necos.Report) error
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var _ necos.ImageService = (*FakeImageService)(nil)
+
+// Calls returns how many times method with given name was called
+func (f *FakeImageService) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+func (f *FakeImageService) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *FakeImageService) GetImages(req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetImages")
+	return f.getImages(req)
+}
+
+// getImages is GetImages without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImages(req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetImagesFunc != nil {
+		return f.GetImagesFunc(req)
+	}
+	return f.getImagesWithContext(context.Background(), req)
+}
+
+func (f *FakeImageService) GetImagesWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetImagesWithContext")
+	return f.getImagesWithContext(ctx, req)
+}
+
+// getImagesWithContext is GetImagesWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImagesWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetImagesWithContextFunc != nil {
+		return f.GetImagesWithContextFunc(ctx, req)
+	}
+	var r0 necos.MultipleContainer[necos.Image]
+	return r0, nil
+}
+
+func (f *FakeImageService) GetRandomImages(req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetRandomImages")
+	return f.getRandomImages(req)
+}
+
+// getRandomImages is GetRandomImages without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getRandomImages(req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetRandomImagesFunc != nil {
+		return f.GetRandomImagesFunc(req)
+	}
+	return f.getRandomImagesWithContext(context.Background(), req)
+}
+
+func (f *FakeImageService) GetRandomImagesWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetRandomImagesWithContext")
+	return f.getRandomImagesWithContext(ctx, req)
+}
+
+// getRandomImagesWithContext is GetRandomImagesWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getRandomImagesWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetRandomImagesWithContextFunc != nil {
+		return f.GetRandomImagesWithContextFunc(ctx, req)
+	}
+	var r0 necos.MultipleContainer[necos.Image]
+	return r0, nil
+}
+
+func (f *FakeImageService) GetRandomImageFileURL(req necos.Request) (string, error) {
+	f.record("GetRandomImageFileURL")
+	return f.getRandomImageFileURL(req)
+}
+
+// getRandomImageFileURL is GetRandomImageFileURL without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getRandomImageFileURL(req necos.Request) (string, error) {
+	if f.GetRandomImageFileURLFunc != nil {
+		return f.GetRandomImageFileURLFunc(req)
+	}
+	return f.getRandomImageFileURLWithContext(context.Background(), req)
+}
+
+func (f *FakeImageService) GetRandomImageFileURLWithContext(ctx context.Context, req necos.Request) (string, error) {
+	f.record("GetRandomImageFileURLWithContext")
+	return f.getRandomImageFileURLWithContext(ctx, req)
+}
+
+// getRandomImageFileURLWithContext is GetRandomImageFileURLWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getRandomImageFileURLWithContext(ctx context.Context, req necos.Request) (string, error) {
+	if f.GetRandomImageFileURLWithContextFunc != nil {
+		return f.GetRandomImageFileURLWithContextFunc(ctx, req)
+	}
+	var r0 string
+	return r0, nil
+}
+
+func (f *FakeImageService) GetRandomImageFile(req necos.Request, dst io.Writer) error {
+	f.record("GetRandomImageFile")
+	return f.getRandomImageFile(req, dst)
+}
+
+// getRandomImageFile is GetRandomImageFile without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getRandomImageFile(req necos.Request, dst io.Writer) error {
+	if f.GetRandomImageFileFunc != nil {
+		return f.GetRandomImageFileFunc(req, dst)
+	}
+	return f.getRandomImageFileWithContext(context.Background(), req, dst)
+}
+
+func (f *FakeImageService) GetRandomImageFileWithContext(ctx context.Context, req necos.Request, dst io.Writer) error {
+	f.record("GetRandomImageFileWithContext")
+	return f.getRandomImageFileWithContext(ctx, req, dst)
+}
+
+// getRandomImageFileWithContext is GetRandomImageFileWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getRandomImageFileWithContext(ctx context.Context, req necos.Request, dst io.Writer) error {
+	if f.GetRandomImageFileWithContextFunc != nil {
+		return f.GetRandomImageFileWithContextFunc(ctx, req, dst)
+	}
+	return nil
+}
+
+func (f *FakeImageService) GetImageByID(id int) (necos.Image, error) {
+	f.record("GetImageByID")
+	return f.getImageByID(id)
+}
+
+// getImageByID is GetImageByID without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageByID(id int) (necos.Image, error) {
+	if f.GetImageByIDFunc != nil {
+		return f.GetImageByIDFunc(id)
+	}
+	return f.getImageByIDWithContext(context.Background(), id)
+}
+
+func (f *FakeImageService) GetImageByIDWithContext(ctx context.Context, id int) (necos.Image, error) {
+	f.record("GetImageByIDWithContext")
+	return f.getImageByIDWithContext(ctx, id)
+}
+
+// getImageByIDWithContext is GetImageByIDWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageByIDWithContext(ctx context.Context, id int) (necos.Image, error) {
+	if f.GetImageByIDWithContextFunc != nil {
+		return f.GetImageByIDWithContextFunc(ctx, id)
+	}
+	var r0 necos.Image
+	return r0, nil
+}
+
+func (f *FakeImageService) GetImageArtist(id int) (necos.Artist, error) {
+	f.record("GetImageArtist")
+	return f.getImageArtist(id)
+}
+
+// getImageArtist is GetImageArtist without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageArtist(id int) (necos.Artist, error) {
+	if f.GetImageArtistFunc != nil {
+		return f.GetImageArtistFunc(id)
+	}
+	return f.getImageArtistWithContext(context.Background(), id)
+}
+
+func (f *FakeImageService) GetImageArtistWithContext(ctx context.Context, id int) (necos.Artist, error) {
+	f.record("GetImageArtistWithContext")
+	return f.getImageArtistWithContext(ctx, id)
+}
+
+// getImageArtistWithContext is GetImageArtistWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageArtistWithContext(ctx context.Context, id int) (necos.Artist, error) {
+	if f.GetImageArtistWithContextFunc != nil {
+		return f.GetImageArtistWithContextFunc(ctx, id)
+	}
+	var r0 necos.Artist
+	return r0, nil
+}
+
+func (f *FakeImageService) GetImageCharacters(id int, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	f.record("GetImageCharacters")
+	return f.getImageCharacters(id, req)
+}
+
+// getImageCharacters is GetImageCharacters without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageCharacters(id int, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	if f.GetImageCharactersFunc != nil {
+		return f.GetImageCharactersFunc(id, req)
+	}
+	return f.getImageCharactersWithContext(context.Background(), id, req)
+}
+
+func (f *FakeImageService) GetImageCharactersWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	f.record("GetImageCharactersWithContext")
+	return f.getImageCharactersWithContext(ctx, id, req)
+}
+
+// getImageCharactersWithContext is GetImageCharactersWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageCharactersWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	if f.GetImageCharactersWithContextFunc != nil {
+		return f.GetImageCharactersWithContextFunc(ctx, id, req)
+	}
+	var r0 necos.MultipleContainer[necos.Character]
+	return r0, nil
+}
+
+func (f *FakeImageService) GetImageTags(id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	f.record("GetImageTags")
+	return f.getImageTags(id, req)
+}
+
+// getImageTags is GetImageTags without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageTags(id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	if f.GetImageTagsFunc != nil {
+		return f.GetImageTagsFunc(id, req)
+	}
+	return f.getImageTagsWithContext(context.Background(), id, req)
+}
+
+func (f *FakeImageService) GetImageTagsWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	f.record("GetImageTagsWithContext")
+	return f.getImageTagsWithContext(ctx, id, req)
+}
+
+// getImageTagsWithContext is GetImageTagsWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) getImageTagsWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	if f.GetImageTagsWithContextFunc != nil {
+		return f.GetImageTagsWithContextFunc(ctx, id, req)
+	}
+	var r0 necos.MultipleContainer[necos.Tag]
+	return r0, nil
+}
+
+func (f *FakeImageService) PostReport(req necos.Report) error {
+	f.record("PostReport")
+	return f.postReport(req)
+}
+
+// postReport is PostReport without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) postReport(req necos.Report) error {
+	if f.PostReportFunc != nil {
+		return f.PostReportFunc(req)
+	}
+	return f.postReportWithContext(context.Background(), req)
+}
+
+func (f *FakeImageService) PostReportWithContext(ctx context.Context, req necos.Report) error {
+	f.record("PostReportWithContext")
+	return f.postReportWithContext(ctx, req)
+}
+
+// postReportWithContext is PostReportWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeImageService) postReportWithContext(ctx context.Context, req necos.Report) error {
+	if f.PostReportWithContextFunc != nil {
+		return f.PostReportWithContextFunc(ctx, req)
+	}
+	return nil
+}
+
+// FakeTagService is configurable fake of necos.TagService
+//
+// every method calls the function in its Func field if it's set, methods without context
+// fall back to their WithContext versions, otherwise zero values are returned.
+// Only the method called from outside is counted, the fallbacks are not
+type FakeTagService struct {
+	GetTagsFunc                 func(req necos.Request) (necos.MultipleContainer[necos.Tag], error)
+	GetTagsWithContextFunc      func(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Tag], error)
+	GetTagByIDFunc              func(id int) (necos.Tag, error)
+	GetTagByIDWithContextFunc   func(ctx context.Context, id int) (necos.Tag, error)
+	GetTagImagesFunc            func(tagID int, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetTagImagesWithContextFunc func(ctx context.Context, tagID int, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var _ necos.TagService = (*FakeTagService)(nil)
+
+// Calls returns how many times method with given name was called
+func (f *FakeTagService) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+func (f *FakeTagService) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *FakeTagService) GetTags(req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	f.record("GetTags")
+	return f.getTags(req)
+}
+
+// getTags is GetTags without counting the call, fakes made of other fakes call it
+func (f *FakeTagService) getTags(req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	if f.GetTagsFunc != nil {
+		return f.GetTagsFunc(req)
+	}
+	return f.getTagsWithContext(context.Background(), req)
+}
+
+func (f *FakeTagService) GetTagsWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	f.record("GetTagsWithContext")
+	return f.getTagsWithContext(ctx, req)
+}
+
+// getTagsWithContext is GetTagsWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeTagService) getTagsWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+	if f.GetTagsWithContextFunc != nil {
+		return f.GetTagsWithContextFunc(ctx, req)
+	}
+	var r0 necos.MultipleContainer[necos.Tag]
+	return r0, nil
+}
+
+func (f *FakeTagService) GetTagByID(id int) (necos.Tag, error) {
+	f.record("GetTagByID")
+	return f.getTagByID(id)
+}
+
+// getTagByID is GetTagByID without counting the call, fakes made of other fakes call it
+func (f *FakeTagService) getTagByID(id int) (necos.Tag, error) {
+	if f.GetTagByIDFunc != nil {
+		return f.GetTagByIDFunc(id)
+	}
+	return f.getTagByIDWithContext(context.Background(), id)
+}
+
+func (f *FakeTagService) GetTagByIDWithContext(ctx context.Context, id int) (necos.Tag, error) {
+	f.record("GetTagByIDWithContext")
+	return f.getTagByIDWithContext(ctx, id)
+}
+
+// getTagByIDWithContext is GetTagByIDWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeTagService) getTagByIDWithContext(ctx context.Context, id int) (necos.Tag, error) {
+	if f.GetTagByIDWithContextFunc != nil {
+		return f.GetTagByIDWithContextFunc(ctx, id)
+	}
+	var r0 necos.Tag
+	return r0, nil
+}
+
+func (f *FakeTagService) GetTagImages(tagID int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetTagImages")
+	return f.getTagImages(tagID, req)
+}
+
+// getTagImages is GetTagImages without counting the call, fakes made of other fakes call it
+func (f *FakeTagService) getTagImages(tagID int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetTagImagesFunc != nil {
+		return f.GetTagImagesFunc(tagID, req)
+	}
+	return f.getTagImagesWithContext(context.Background(), tagID, req)
+}
+
+func (f *FakeTagService) GetTagImagesWithContext(ctx context.Context, tagID int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetTagImagesWithContext")
+	return f.getTagImagesWithContext(ctx, tagID, req)
+}
+
+// getTagImagesWithContext is GetTagImagesWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeTagService) getTagImagesWithContext(ctx context.Context, tagID int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetTagImagesWithContextFunc != nil {
+		return f.GetTagImagesWithContextFunc(ctx, tagID, req)
+	}
+	var r0 necos.MultipleContainer[necos.Image]
+	return r0, nil
+}
+
+// FakeArtistService is configurable fake of necos.ArtistService
+//
+// every method calls the function in its Func field if it's set, methods without context
+// fall back to their WithContext versions, otherwise zero values are returned.
+// Only the method called from outside is counted, the fallbacks are not
+type FakeArtistService struct {
+	GetArtistsFunc                 func(req necos.Request) (necos.MultipleContainer[necos.Artist], error)
+	GetArtistsWithContextFunc      func(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Artist], error)
+	GetArtistByIDFunc              func(id int) (necos.Artist, error)
+	GetArtistByIDWithContextFunc   func(ctx context.Context, id int) (necos.Artist, error)
+	GetArtistImagesFunc            func(id int, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetArtistImagesWithContextFunc func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var _ necos.ArtistService = (*FakeArtistService)(nil)
+
+// Calls returns how many times method with given name was called
+func (f *FakeArtistService) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+func (f *FakeArtistService) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *FakeArtistService) GetArtists(req necos.Request) (necos.MultipleContainer[necos.Artist], error) {
+	f.record("GetArtists")
+	return f.getArtists(req)
+}
+
+// getArtists is GetArtists without counting the call, fakes made of other fakes call it
+func (f *FakeArtistService) getArtists(req necos.Request) (necos.MultipleContainer[necos.Artist], error) {
+	if f.GetArtistsFunc != nil {
+		return f.GetArtistsFunc(req)
+	}
+	return f.getArtistsWithContext(context.Background(), req)
+}
+
+func (f *FakeArtistService) GetArtistsWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Artist], error) {
+	f.record("GetArtistsWithContext")
+	return f.getArtistsWithContext(ctx, req)
+}
+
+// getArtistsWithContext is GetArtistsWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeArtistService) getArtistsWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Artist], error) {
+	if f.GetArtistsWithContextFunc != nil {
+		return f.GetArtistsWithContextFunc(ctx, req)
+	}
+	var r0 necos.MultipleContainer[necos.Artist]
+	return r0, nil
+}
+
+func (f *FakeArtistService) GetArtistByID(id int) (necos.Artist, error) {
+	f.record("GetArtistByID")
+	return f.getArtistByID(id)
+}
+
+// getArtistByID is GetArtistByID without counting the call, fakes made of other fakes call it
+func (f *FakeArtistService) getArtistByID(id int) (necos.Artist, error) {
+	if f.GetArtistByIDFunc != nil {
+		return f.GetArtistByIDFunc(id)
+	}
+	return f.getArtistByIDWithContext(context.Background(), id)
+}
+
+func (f *FakeArtistService) GetArtistByIDWithContext(ctx context.Context, id int) (necos.Artist, error) {
+	f.record("GetArtistByIDWithContext")
+	return f.getArtistByIDWithContext(ctx, id)
+}
+
+// getArtistByIDWithContext is GetArtistByIDWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeArtistService) getArtistByIDWithContext(ctx context.Context, id int) (necos.Artist, error) {
+	if f.GetArtistByIDWithContextFunc != nil {
+		return f.GetArtistByIDWithContextFunc(ctx, id)
+	}
+	var r0 necos.Artist
+	return r0, nil
+}
+
+func (f *FakeArtistService) GetArtistImages(id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetArtistImages")
+	return f.getArtistImages(id, req)
+}
+
+// getArtistImages is GetArtistImages without counting the call, fakes made of other fakes call it
+func (f *FakeArtistService) getArtistImages(id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetArtistImagesFunc != nil {
+		return f.GetArtistImagesFunc(id, req)
+	}
+	return f.getArtistImagesWithContext(context.Background(), id, req)
+}
+
+func (f *FakeArtistService) GetArtistImagesWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetArtistImagesWithContext")
+	return f.getArtistImagesWithContext(ctx, id, req)
+}
+
+// getArtistImagesWithContext is GetArtistImagesWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeArtistService) getArtistImagesWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetArtistImagesWithContextFunc != nil {
+		return f.GetArtistImagesWithContextFunc(ctx, id, req)
+	}
+	var r0 necos.MultipleContainer[necos.Image]
+	return r0, nil
+}
+
+// FakeCharacterService is configurable fake of necos.CharacterService
+//
+// every method calls the function in its Func field if it's set, methods without context
+// fall back to their WithContext versions, otherwise zero values are returned.
+// Only the method called from outside is counted, the fallbacks are not
+type FakeCharacterService struct {
+	GetCharactersFunc                 func(req necos.Request) (necos.MultipleContainer[necos.Character], error)
+	GetCharactersWithContextFunc      func(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Character], error)
+	GetCharacterByIDFunc              func(id int) (necos.Character, error)
+	GetCharacterByIDWithContextFunc   func(ctx context.Context, id int) (necos.Character, error)
+	GetCharacterImagesFunc            func(id int, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+	GetCharacterImagesWithContextFunc func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error)
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var _ necos.CharacterService = (*FakeCharacterService)(nil)
+
+// Calls returns how many times method with given name was called
+func (f *FakeCharacterService) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+func (f *FakeCharacterService) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *FakeCharacterService) GetCharacters(req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	f.record("GetCharacters")
+	return f.getCharacters(req)
+}
+
+// getCharacters is GetCharacters without counting the call, fakes made of other fakes call it
+func (f *FakeCharacterService) getCharacters(req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	if f.GetCharactersFunc != nil {
+		return f.GetCharactersFunc(req)
+	}
+	return f.getCharactersWithContext(context.Background(), req)
+}
+
+func (f *FakeCharacterService) GetCharactersWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	f.record("GetCharactersWithContext")
+	return f.getCharactersWithContext(ctx, req)
+}
+
+// getCharactersWithContext is GetCharactersWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeCharacterService) getCharactersWithContext(ctx context.Context, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+	if f.GetCharactersWithContextFunc != nil {
+		return f.GetCharactersWithContextFunc(ctx, req)
+	}
+	var r0 necos.MultipleContainer[necos.Character]
+	return r0, nil
+}
+
+func (f *FakeCharacterService) GetCharacterByID(id int) (necos.Character, error) {
+	f.record("GetCharacterByID")
+	return f.getCharacterByID(id)
+}
+
+// getCharacterByID is GetCharacterByID without counting the call, fakes made of other fakes call it
+func (f *FakeCharacterService) getCharacterByID(id int) (necos.Character, error) {
+	if f.GetCharacterByIDFunc != nil {
+		return f.GetCharacterByIDFunc(id)
+	}
+	return f.getCharacterByIDWithContext(context.Background(), id)
+}
+
+func (f *FakeCharacterService) GetCharacterByIDWithContext(ctx context.Context, id int) (necos.Character, error) {
+	f.record("GetCharacterByIDWithContext")
+	return f.getCharacterByIDWithContext(ctx, id)
+}
+
+// getCharacterByIDWithContext is GetCharacterByIDWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeCharacterService) getCharacterByIDWithContext(ctx context.Context, id int) (necos.Character, error) {
+	if f.GetCharacterByIDWithContextFunc != nil {
+		return f.GetCharacterByIDWithContextFunc(ctx, id)
+	}
+	var r0 necos.Character
+	return r0, nil
+}
+
+func (f *FakeCharacterService) GetCharacterImages(id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetCharacterImages")
+	return f.getCharacterImages(id, req)
+}
+
+// getCharacterImages is GetCharacterImages without counting the call, fakes made of other fakes call it
+func (f *FakeCharacterService) getCharacterImages(id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetCharacterImagesFunc != nil {
+		return f.GetCharacterImagesFunc(id, req)
+	}
+	return f.getCharacterImagesWithContext(context.Background(), id, req)
+}
+
+func (f *FakeCharacterService) GetCharacterImagesWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	f.record("GetCharacterImagesWithContext")
+	return f.getCharacterImagesWithContext(ctx, id, req)
+}
+
+// getCharacterImagesWithContext is GetCharacterImagesWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeCharacterService) getCharacterImagesWithContext(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+	if f.GetCharacterImagesWithContextFunc != nil {
+		return f.GetCharacterImagesWithContextFunc(ctx, id, req)
+	}
+	var r0 necos.MultipleContainer[necos.Image]
+	return r0, nil
+}
+
+// FakeDownloader is configurable fake of necos.Downloader
+//
+// every method calls the function in its Func field if it's set, methods without context
+// fall back to their WithContext versions, otherwise zero values are returned.
+// Only the method called from outside is counted, the fallbacks are not
+type FakeDownloader struct {
+	DownloadAppendFunc            func(ctx context.Context, url string, dst io.Writer) error
+	DownloadFunc                  func(ctx context.Context, url string, dst io.WriteCloser) error
+	DownloadImageFunc             func(im *necos.Image, dst io.WriteCloser) error
+	DownloadImageWithContextFunc  func(ctx context.Context, im *necos.Image, dst io.WriteCloser) error
+	DownloadSampleFunc            func(im *necos.Image, dst io.WriteCloser) error
+	DownloadSampleWithContextFunc func(ctx context.Context, im *necos.Image, dst io.WriteCloser) error
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var _ necos.Downloader = (*FakeDownloader)(nil)
+
+// Calls returns how many times method with given name was called
+func (f *FakeDownloader) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+func (f *FakeDownloader) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *FakeDownloader) DownloadAppend(ctx context.Context, url string, dst io.Writer) error {
+	f.record("DownloadAppend")
+	return f.downloadAppend(ctx, url, dst)
+}
+
+// downloadAppend is DownloadAppend without counting the call, fakes made of other fakes call it
+func (f *FakeDownloader) downloadAppend(ctx context.Context, url string, dst io.Writer) error {
+	if f.DownloadAppendFunc != nil {
+		return f.DownloadAppendFunc(ctx, url, dst)
+	}
+	return nil
+}
+
+func (f *FakeDownloader) Download(ctx context.Context, url string, dst io.WriteCloser) error {
+	f.record("Download")
+	return f.download(ctx, url, dst)
+}
+
+// download is Download without counting the call, fakes made of other fakes call it
+func (f *FakeDownloader) download(ctx context.Context, url string, dst io.WriteCloser) error {
+	if f.DownloadFunc != nil {
+		return f.DownloadFunc(ctx, url, dst)
+	}
+	return nil
+}
+
+func (f *FakeDownloader) DownloadImage(im *necos.Image, dst io.WriteCloser) error {
+	f.record("DownloadImage")
+	return f.downloadImage(im, dst)
+}
+
+// downloadImage is DownloadImage without counting the call, fakes made of other fakes call it
+func (f *FakeDownloader) downloadImage(im *necos.Image, dst io.WriteCloser) error {
+	if f.DownloadImageFunc != nil {
+		return f.DownloadImageFunc(im, dst)
+	}
+	return f.downloadImageWithContext(context.Background(), im, dst)
+}
+
+func (f *FakeDownloader) DownloadImageWithContext(ctx context.Context, im *necos.Image, dst io.WriteCloser) error {
+	f.record("DownloadImageWithContext")
+	return f.downloadImageWithContext(ctx, im, dst)
+}
+
+// downloadImageWithContext is DownloadImageWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeDownloader) downloadImageWithContext(ctx context.Context, im *necos.Image, dst io.WriteCloser) error {
+	if f.DownloadImageWithContextFunc != nil {
+		return f.DownloadImageWithContextFunc(ctx, im, dst)
+	}
+	return nil
+}
+
+func (f *FakeDownloader) DownloadSample(im *necos.Image, dst io.WriteCloser) error {
+	f.record("DownloadSample")
+	return f.downloadSample(im, dst)
+}
+
+// downloadSample is DownloadSample without counting the call, fakes made of other fakes call it
+func (f *FakeDownloader) downloadSample(im *necos.Image, dst io.WriteCloser) error {
+	if f.DownloadSampleFunc != nil {
+		return f.DownloadSampleFunc(im, dst)
+	}
+	return f.downloadSampleWithContext(context.Background(), im, dst)
+}
+
+func (f *FakeDownloader) DownloadSampleWithContext(ctx context.Context, im *necos.Image, dst io.WriteCloser) error {
+	f.record("DownloadSampleWithContext")
+	return f.downloadSampleWithContext(ctx, im, dst)
+}
+
+// downloadSampleWithContext is DownloadSampleWithContext without counting the call, fakes made of other fakes call it
+func (f *FakeDownloader) downloadSampleWithContext(ctx context.Context, im *necos.Image, dst io.WriteCloser) error {
+	if f.DownloadSampleWithContextFunc != nil {
+		return f.DownloadSampleWithContextFunc(ctx, im, dst)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package necostest
+
+import (
+	"context"
+	"fmt"
+	"github.com/rinnothing/go-necos"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// Fakes are fakes of all the services backed by Dataset
+//
+// they answer like Server does, but without HTTP, and every Func can still be replaced
+type Fakes struct {
+	Images     *FakeImageService
+	Tags       *FakeTagService
+	Artists    *FakeArtistService
+	Characters *FakeCharacterService
+	Downloader *FakeDownloader
+}
+
+// NewFakes creates Fakes serving data from d
+func NewFakes(d *Dataset) *Fakes {
+	f := &Fakes{
+		Images:     &FakeImageService{},
+		Tags:       &FakeTagService{},
+		Artists:    &FakeArtistService{},
+		Characters: &FakeCharacterService{},
+		Downloader: &FakeDownloader{},
+	}
+
+	f.Images.GetImagesWithContextFunc = func(_ context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+		return listPage(necos.Images, filter(d.Images, req, imageMatches), req)
+	}
+	f.Images.GetRandomImagesWithContextFunc = func(_ context.Context, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+		images, err := listPage(necos.RandomImages, filter(d.Images, req, imageMatches), necos.Request{"limit": req["limit"]})
+		images.Count = len(images.Items)
+		return images, err
+	}
+	f.Images.GetImageByIDWithContextFunc = func(_ context.Context, id int) (necos.Image, error) {
+		return findByID(necos.ImageByID, d.Images, id, func(im necos.Image) int { return im.ID })
+	}
+	f.Images.GetImageArtistWithContextFunc = func(ctx context.Context, id int) (necos.Artist, error) {
+		im, err := f.Images.getImageByIDWithContext(ctx, id)
+		if err == nil && im.Artist.ID == 0 {
+			err = notFound(necos.ImageArtist, id)
+		}
+		return im.Artist, err
+	}
+	f.Images.GetImageCharactersWithContextFunc = func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+		im, err := f.Images.getImageByIDWithContext(ctx, id)
+		if err != nil {
+			return necos.MultipleContainer[necos.Character]{}, err
+		}
+		return listPage(necos.ImageCharacters, im.Characters, req)
+	}
+	f.Images.GetImageTagsWithContextFunc = func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+		im, err := f.Images.getImageByIDWithContext(ctx, id)
+		if err != nil {
+			return necos.MultipleContainer[necos.Tag]{}, err
+		}
+		return listPage(necos.ImageTags, im.Tags, req)
+	}
+
+	f.Tags.GetTagsWithContextFunc = func(_ context.Context, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+		return listPage(necos.Tags, filter(d.Tags, req, tagMatches), req)
+	}
+	f.Tags.GetTagByIDWithContextFunc = func(_ context.Context, id int) (necos.Tag, error) {
+		return findByID(necos.TagByID, d.Tags, id, func(t necos.Tag) int { return t.ID })
+	}
+	f.Tags.GetTagImagesWithContextFunc = func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+		if _, err := f.Tags.getTagByIDWithContext(ctx, id); err != nil {
+			return necos.MultipleContainer[necos.Image]{}, err
+		}
+		return listPage(necos.TagImages, imagesOf(d, func(im necos.Image) bool {
+			return slices.ContainsFunc(im.Tags, func(t necos.Tag) bool { return t.ID == id })
+		}), req)
+	}
+
+	f.Artists.GetArtistsWithContextFunc = func(_ context.Context, req necos.Request) (necos.MultipleContainer[necos.Artist], error) {
+		return listPage(necos.Artists, filter(d.Artists, req, artistMatches), req)
+	}
+	f.Artists.GetArtistByIDWithContextFunc = func(_ context.Context, id int) (necos.Artist, error) {
+		return findByID(necos.ArtistByID, d.Artists, id, func(a necos.Artist) int { return a.ID })
+	}
+	f.Artists.GetArtistImagesWithContextFunc = func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+		if _, err := f.Artists.getArtistByIDWithContext(ctx, id); err != nil {
+			return necos.MultipleContainer[necos.Image]{}, err
+		}
+		return listPage(necos.ArtistImages, imagesOf(d, func(im necos.Image) bool { return im.Artist.ID == id }), req)
+	}
+
+	f.Characters.GetCharactersWithContextFunc = func(_ context.Context, req necos.Request) (necos.MultipleContainer[necos.Character], error) {
+		return listPage(necos.Characters, filter(d.Characters, req, characterMatches), req)
+	}
+	f.Characters.GetCharacterByIDWithContextFunc = func(_ context.Context, id int) (necos.Character, error) {
+		return findByID(necos.CharacterByID, d.Characters, id, func(c necos.Character) int { return c.ID })
+	}
+	f.Characters.GetCharacterImagesWithContextFunc = func(ctx context.Context, id int, req necos.Request) (necos.MultipleContainer[necos.Image], error) {
+		if _, err := f.Characters.getCharacterByIDWithContext(ctx, id); err != nil {
+			return necos.MultipleContainer[necos.Image]{}, err
+		}
+		return listPage(necos.CharacterImages, imagesOf(d, func(im necos.Image) bool {
+			return slices.ContainsFunc(im.Characters, func(c necos.Character) bool { return c.ID == id })
+		}), req)
+	}
+
+	f.Downloader.DownloadAppendFunc = func(_ context.Context, rawURL string, dst io.Writer) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		content, ok := d.Files[u.Path]
+		if !ok {
+			return &necos.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found", Method: http.MethodGet, Path: rawURL}
+		}
+		_, err = dst.Write(content)
+		return err
+	}
+	f.Downloader.DownloadFunc = func(ctx context.Context, url string, dst io.WriteCloser) error {
+		if err := f.Downloader.downloadAppend(ctx, url, dst); err != nil {
+			return err
+		}
+		return dst.Close()
+	}
+	f.Downloader.DownloadImageWithContextFunc = func(ctx context.Context, im *necos.Image, dst io.WriteCloser) error {
+		return f.Downloader.download(ctx, im.ImageURL, dst)
+	}
+	f.Downloader.DownloadSampleWithContextFunc = func(ctx context.Context, im *necos.Image, dst io.WriteCloser) error {
+		return f.Downloader.download(ctx, im.SampleURL, dst)
+	}
+	return f
+}
+
+// listPage selects page of items by limit and offset of req, returning error like API does
+func listPage[T any](path string, items []T, req necos.Request) (necos.MultipleContainer[T], error) {
+	limit, offset := necos.MaxPageSize, 0
+	if value := req.Get("limit"); value != "" {
+		limit, _ = strconv.Atoi(value)
+	}
+	if value := req.Get("offset"); value != "" {
+		offset, _ = strconv.Atoi(value)
+	}
+
+	if err := (necos.Page{Limit: limit, Offset: offset}).Validate(); err != nil || limit == 0 {
+		return necos.MultipleContainer[T]{}, &necos.APIError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Status:     "422 Unprocessable Entity",
+			Method:     http.MethodGet,
+			Path:       path,
+			Query:      req,
+		}
+	}
+	return page(items, limit, offset), nil
+}
+
+func findByID[T any](template string, items []T, id int, idOf func(T) int) (T, error) {
+	if item := find(items, func(item T) bool { return idOf(item) == id }); item != nil {
+		return *item, nil
+	}
+
+	var zero T
+	return zero, notFound(template, id)
+}
+
+func imagesOf(d *Dataset, belongs func(necos.Image) bool) []necos.Image {
+	var images []necos.Image
+	for _, im := range d.Images {
+		if belongs(im) {
+			images = append(images, im)
+		}
+	}
+	return images
+}
+
+func notFound(template string, id int) *necos.APIError {
+	return &necos.APIError{
+		StatusCode: http.StatusNotFound,
+		Status:     "404 Not Found",
+		Method:     http.MethodGet,
+		Path:       fmt.Sprintf(template, id),
+		Detail:     "Not Found",
+	}
+}
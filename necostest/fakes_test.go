@@ -0,0 +1,81 @@
+package necostest
+
+import (
+	"context"
+	"errors"
+	"github.com/rinnothing/go-necos"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// firstTagName is an example of code depending on narrow interface
+func firstTagName(tags necos.TagService, search string) (string, error) {
+	found, err := tags.GetTags(necos.AddFields(nil, "search", search, "limit", 1))
+	if err != nil {
+		return "", err
+	}
+	if len(found.Items) == 0 {
+		return "", nil
+	}
+	return found.Items[0].Name, nil
+}
+
+func TestFakeFuncs(t *testing.T) {
+	fake := &FakeTagService{}
+
+	// zero values by default
+	name, err := firstTagName(fake, "cat")
+	require.NoError(t, err)
+	require.Empty(t, name)
+
+	fake.GetTagsWithContextFunc = func(_ context.Context, req necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+		return necos.MultipleContainer[necos.Tag]{Items: []necos.Tag{{Name: req.Get("search")}}, Count: 1}, nil
+	}
+	name, err = firstTagName(fake, "cat")
+	require.NoError(t, err)
+	require.Equal(t, "cat", name)
+
+	broken := errors.New("broken")
+	fake.GetTagsFunc = func(necos.Request) (necos.MultipleContainer[necos.Tag], error) {
+		return necos.MultipleContainer[necos.Tag]{}, broken
+	}
+	_, err = firstTagName(fake, "cat")
+	require.ErrorIs(t, err, broken)
+
+	// falling back to GetTagsWithContext isn't counted as its call
+	require.Equal(t, 3, fake.Calls("GetTags"))
+	require.Zero(t, fake.Calls("GetTagsWithContext"))
+}
+
+func TestFakesFromDataset(t *testing.T) {
+	d := NewDataset(7, DefaultSizes)
+	f := NewFakes(d)
+
+	name, err := firstTagName(f.Tags, d.Tags[3].Name)
+	require.NoError(t, err)
+	require.Equal(t, d.Tags[3].Name, name)
+
+	images, err := f.Images.GetImages(necos.AddFields(nil, "limit", 10, "offset", 5))
+	require.NoError(t, err)
+	require.Equal(t, d.Images[5:15], images.Items)
+	require.Equal(t, len(d.Images), images.Count)
+
+	_, err = f.Images.GetImages(necos.AddFields(nil, "limit", 1000))
+	require.True(t, necos.IsClientError(err))
+
+	artist, err := f.Images.GetImageArtist(d.Images[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, d.Images[0].Artist, artist)
+	require.Equal(t, 1, f.Images.Calls("GetImageArtist"))
+	require.Zero(t, f.Images.Calls("GetImageByIDWithContext"))
+
+	_, err = f.Characters.GetCharacterByID(100500)
+	require.True(t, necos.IsNotFound(err))
+
+	var content []byte
+	require.NoError(t, f.Downloader.DownloadImage(&d.Images[0], necos.SaveToSlice(&content)))
+	require.Equal(t, d.Files[d.Images[0].ImageURL], content)
+	require.Equal(t, 1, f.Downloader.Calls("DownloadImage"))
+	require.Zero(t, f.Downloader.Calls("Download"))
+	require.Zero(t, f.Downloader.Calls("DownloadAppend"))
+}
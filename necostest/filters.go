@@ -0,0 +1,125 @@
+package necostest
+
+import (
+	"github.com/rinnothing/go-necos"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// filter returns items matching the query
+func filter[T any](items []T, query url.Values, matches func(T, url.Values) bool) []T {
+	res := make([]T, 0, len(items))
+	for _, item := range items {
+		if matches(item, query) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// find returns pointer to the first item satisfying pred, nil if there's none
+func find[T any](items []T, pred func(T) bool) *T {
+	for i := range items {
+		if pred(items[i]) {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// boolMatches checks value against boolean query parameter, missing parameter matches anything
+func boolMatches(query url.Values, key string, value bool) bool {
+	if !query.Has(key) {
+		return true
+	}
+	want, err := strconv.ParseBool(query.Get(key))
+	return err != nil || want == value
+}
+
+// stringMatches checks value against string query parameter case-insensitively
+func stringMatches(query url.Values, key string, value string) bool {
+	return !query.Has(key) || strings.EqualFold(query.Get(key), value)
+}
+
+// idsMatch checks that every ID of query parameter is present in ids
+func idsMatch(query url.Values, key string, ids []int) bool {
+	for _, v := range query[key] {
+		id, err := strconv.Atoi(v)
+		if err != nil || !slices.Contains(ids, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchMatches checks whether search parameter is a substring of any of texts
+func searchMatches(query url.Values, texts ...string) bool {
+	search := strings.ToLower(query.Get("search"))
+	if search == "" {
+		return true
+	}
+
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func imageMatches(im necos.Image, query url.Values) bool {
+	if ratings := query["rating"]; len(ratings) > 0 && !slices.Contains(ratings, string(im.Rating)) {
+		return false
+	}
+	if artist := query.Get("artist"); artist != "" && artist != strconv.Itoa(im.Artist.ID) {
+		return false
+	}
+
+	var characters, tags []int
+	for _, c := range im.Characters {
+		characters = append(characters, c.ID)
+	}
+	for _, t := range im.Tags {
+		tags = append(tags, t.ID)
+	}
+
+	return boolMatches(query, "is_original", im.IsOriginal) &&
+		boolMatches(query, "is_screenshot", im.IsScreenshot) &&
+		boolMatches(query, "is_flagged", im.IsFlagged) &&
+		boolMatches(query, "is_animated", im.IsAnimated) &&
+		idsMatch(query, "character", characters) &&
+		idsMatch(query, "tag", tags)
+}
+
+func tagMatches(t necos.Tag, query url.Values) bool {
+	return searchMatches(query, t.Name, t.Description) &&
+		boolMatches(query, "is_nsfw", t.IsNSFW)
+}
+
+func artistMatches(a necos.Artist, query url.Values) bool {
+	return searchMatches(query, append([]string{a.Name}, a.Aliases...)...) &&
+		boolMatches(query, "policy_repost", a.PolicyRepost) &&
+		boolMatches(query, "policy_credit", a.PolicyCredit) &&
+		boolMatches(query, "policy_ai", a.PolicyAI)
+}
+
+func characterMatches(c necos.Character, query url.Values) bool {
+	if occupations := query["occupation"]; len(occupations) > 0 &&
+		!slices.ContainsFunc(occupations, func(o string) bool { return slices.Contains(c.Occupations, o) }) {
+		return false
+	}
+	if ages := query["age"]; len(ages) > 0 &&
+		!slices.ContainsFunc(ages, func(a string) bool {
+			age, err := strconv.Atoi(a)
+			return err == nil && slices.Contains(c.Ages, age)
+		}) {
+		return false
+	}
+
+	return searchMatches(query, c.Name, c.Description) &&
+		stringMatches(query, "gender", string(c.Gender)) &&
+		stringMatches(query, "species", c.Species) &&
+		stringMatches(query, "nationality", c.Nationality)
+}
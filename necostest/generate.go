@@ -0,0 +1,3 @@
+package necostest
+
+//go:generate go run ./internal/genfakes -src ../services.go -out fakes.go
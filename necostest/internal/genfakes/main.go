@@ -0,0 +1,245 @@
+// Command genfakes generates fakes of service interfaces declared in necos/services.go
+//
+// usage: go run ./internal/genfakes -src ../services.go -out fakes.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+const pkgName = "necos"
+
+type method struct {
+	name    string
+	params  []param
+	results []string
+}
+
+type param struct {
+	name string
+	typ  string
+}
+
+type service struct {
+	name    string
+	methods []method
+}
+
+func main() {
+	src := flag.String("src", "../services.go", "file with service interfaces")
+	out := flag.String("out", "fakes.go", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, *src, nil, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imports := map[string]bool{"sync": true}
+	services := collect(file, imports)
+
+	code, err := format.Source(generate(services, imports))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect finds interfaces in file and describes their methods
+func collect(file *ast.File, imports map[string]bool) []service {
+	var services []service
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			iface, ok := ts.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+
+			s := service{name: ts.Name.Name}
+			for _, field := range iface.Methods.List {
+				fn := field.Type.(*ast.FuncType)
+				m := method{name: field.Names[0].Name}
+
+				for _, p := range fn.Params.List {
+					typ := typeString(p.Type, imports)
+					for _, name := range p.Names {
+						m.params = append(m.params, param{name: name.Name, typ: typ})
+					}
+				}
+				if fn.Results != nil {
+					for _, r := range fn.Results.List {
+						m.results = append(m.results, typeString(r.Type, imports))
+					}
+				}
+				s.methods = append(s.methods, m)
+			}
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// typeString prints type expression qualifying identifiers declared in necos
+func typeString(expr ast.Expr, imports map[string]bool) string {
+	return types.ExprString(qualify(expr, imports))
+}
+
+func qualify(expr ast.Expr, imports map[string]bool) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(e.Name) {
+			imports["github.com/rinnothing/go-necos"] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: e}
+		}
+		return e
+	case *ast.SelectorExpr:
+		imports[e.X.(*ast.Ident).Name] = true
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(e.X, imports)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: qualify(e.Elt, imports)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: qualify(e.X, imports), Index: qualify(e.Index, imports)}
+	default:
+		log.Fatalf("unsupported type %T", expr)
+		return nil
+	}
+}
+
+func generate(services []service, imports map[string]bool) []byte {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by genfakes from services.go; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package necostest")
+	fmt.Fprintln(&b)
+
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintln(&b, "import (")
+	for _, path := range paths {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	fmt.Fprintln(&b, ")")
+
+	for _, s := range services {
+		generateService(&b, s)
+	}
+	return b.Bytes()
+}
+
+func generateService(b *bytes.Buffer, s service) {
+	fake := "Fake" + s.name
+	names := make(map[string]bool)
+	for _, m := range s.methods {
+		names[m.name] = true
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "// %s is configurable fake of necos.%s\n", fake, s.name)
+	fmt.Fprintln(b, "//")
+	fmt.Fprintln(b, "// every method calls the function in its Func field if it's set, methods without context")
+	fmt.Fprintln(b, "// fall back to their WithContext versions, otherwise zero values are returned.")
+	fmt.Fprintln(b, "// Only the method called from outside is counted, the fallbacks are not")
+	fmt.Fprintf(b, "type %s struct {\n", fake)
+	for _, m := range s.methods {
+		fmt.Fprintf(b, "\t%sFunc func(%s) %s\n", m.name, m.paramList(), m.resultList())
+	}
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "\tmu    sync.Mutex")
+	fmt.Fprintln(b, "\tcalls map[string]int")
+	fmt.Fprintln(b, "}")
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "var _ necos.%s = (*%s)(nil)\n", s.name, fake)
+
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "// Calls returns how many times method with given name was called")
+	fmt.Fprintf(b, "func (f *%s) Calls(method string) int {\n", fake)
+	fmt.Fprintln(b, "\tf.mu.Lock()\n\tdefer f.mu.Unlock()\n\n\treturn f.calls[method]\n}")
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "func (f *%s) record(method string) {\n", fake)
+	fmt.Fprintln(b, "\tf.mu.Lock()\n\tdefer f.mu.Unlock()")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "\tif f.calls == nil {\n\t\tf.calls = make(map[string]int)\n\t}\n\tf.calls[method]++\n}")
+
+	for _, m := range s.methods {
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "func (f *%s) %s(%s) %s {\n", fake, m.name, m.paramList(), m.resultList())
+		fmt.Fprintf(b, "\tf.record(%q)\n", m.name)
+		fmt.Fprintf(b, "\treturn f.%s(%s)\n}\n", unexported(m.name), m.argList())
+
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "// %s is %s without counting the call, fakes made of other fakes call it\n", unexported(m.name), m.name)
+		fmt.Fprintf(b, "func (f *%s) %s(%s) %s {\n", fake, unexported(m.name), m.paramList(), m.resultList())
+		fmt.Fprintf(b, "\tif f.%sFunc != nil {\n\t\treturn f.%sFunc(%s)\n\t}\n", m.name, m.name, m.argList())
+
+		if names[m.name+"WithContext"] {
+			fmt.Fprintf(b, "\treturn f.%s(context.Background(), %s)\n}\n", unexported(m.name+"WithContext"), m.argList())
+			continue
+		}
+
+		var zeros []string
+		for i, r := range m.results {
+			if r == "error" {
+				zeros = append(zeros, "nil")
+				continue
+			}
+			fmt.Fprintf(b, "\tvar r%d %s\n", i, r)
+			zeros = append(zeros, fmt.Sprintf("r%d", i))
+		}
+		fmt.Fprintf(b, "\treturn %s\n}\n", strings.Join(zeros, ", "))
+	}
+}
+
+// unexported lowercases the first letter of name
+func unexported(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func (m method) paramList() string {
+	parts := make([]string, len(m.params))
+	for i, p := range m.params {
+		parts[i] = p.name + " " + p.typ
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m method) argList() string {
+	parts := make([]string, len(m.params))
+	for i, p := range m.params {
+		parts[i] = p.name
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m method) resultList() string {
+	if len(m.results) == 1 {
+		return m.results[0]
+	}
+	return "(" + strings.Join(m.results, ", ") + ")"
+}
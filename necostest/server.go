@@ -0,0 +1,389 @@
+// Package necostest provides fake Nekos API server to test code using necos.Client without network
+package necostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rinnothing/go-necos"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is fake Nekos API implementing all the endpoints of necos over in-memory Dataset
+//
+// it also serves image files, so everything including downloads can be tested
+type Server struct {
+	*httptest.Server
+
+	// Data must not be modified while Server is running
+	Data *Dataset
+
+	mu       sync.Mutex
+	random   *rand.Rand
+	faults   []*fault
+	reports  []necos.Report
+	requests []string
+}
+
+// fault is the injected error
+type fault struct {
+	path   string
+	status int
+	times  int
+}
+
+// NewServer starts Server with Dataset of DefaultSizes generated from seed
+func NewServer(seed uint64) *Server {
+	return NewServerWithData(seed, NewDataset(seed, DefaultSizes))
+}
+
+// NewServerWithData starts Server with given Dataset, seed is used for random endpoints
+func NewServerWithData(seed uint64, data *Dataset) *Server {
+	s := &Server{
+		Data:   data,
+		random: rand.New(rand.NewPCG(seed, seed+1)),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client returns necos.Client configured to use Server
+func (s *Server) Client() *necos.Client {
+	c := necos.NewClient()
+	c.Domain = s.URL
+	c.Client = *s.Server.Client()
+	return c
+}
+
+// Fail makes the next times requests to path fail with given status, times < 0 means forever
+//
+// path can be either exact ("/images/tags/3") or endpoint template of necos (necos.TagByID)
+func (s *Server) Fail(path string, status int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{path: path, status: status, times: times})
+}
+
+// Reset removes all injected errors and forgets received requests and reports
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+	s.reports = nil
+	s.requests = nil
+}
+
+// Reports returns queries of received PostReport calls
+func (s *Server) Reports() []necos.Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.reports)
+}
+
+// Requests returns received requests as "METHOD path?query"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// injected returns status of injected error for the path, 0 if there's none
+func (s *Server) injected(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template := templateOf(path)
+	for i, f := range s.faults {
+		if f.path != path && f.path != template {
+			continue
+		}
+
+		if f.times > 0 {
+			f.times--
+			if f.times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return f.status
+	}
+	return 0
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	s.mu.Unlock()
+
+	if status := s.injected(r.URL.Path); status != 0 {
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/files/") {
+		s.serveFile(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == necos.ReportImage {
+		s.mu.Lock()
+		s.reports = append(s.reports, r.URL.Query())
+		s.mu.Unlock()
+		writeJSON(w, struct{}{})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	id := 0
+	if len(segments) >= 2 {
+		id, _ = strconv.Atoi(segments[len(segments)-1])
+		if len(segments) >= 3 && id == 0 {
+			id, _ = strconv.Atoi(segments[len(segments)-2])
+		}
+	}
+
+	switch templateOf(r.URL.Path) {
+	case necos.Images:
+		s.serveList(w, query, s.absolute(filter(s.Data.Images, query, imageMatches)))
+	case necos.RandomImages:
+		s.serveRandom(w, query)
+	case necos.RandomImageFile:
+		s.serveRandomFile(w, r)
+	case necos.ImageByID:
+		writeOne(w, s.findImage(id))
+	case necos.ImageArtist:
+		im := s.findImage(id)
+		if im == nil || im.Artist.ID == 0 {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeOne(w, &im.Artist)
+	case necos.ImageCharacters:
+		if im := s.findImage(id); im != nil {
+			s.serveList(w, query, im.Characters)
+			return
+		}
+		writeError(w, http.StatusNotFound, "Not Found")
+	case necos.ImageTags:
+		if im := s.findImage(id); im != nil {
+			s.serveList(w, query, im.Tags)
+			return
+		}
+		writeError(w, http.StatusNotFound, "Not Found")
+	case necos.Tags:
+		s.serveList(w, query, filter(s.Data.Tags, query, tagMatches))
+	case necos.TagByID:
+		writeOne(w, find(s.Data.Tags, func(t necos.Tag) bool { return t.ID == id }))
+	case necos.TagImages:
+		s.serveImagesOf(w, query, find(s.Data.Tags, func(t necos.Tag) bool { return t.ID == id }) != nil,
+			func(im necos.Image) bool {
+				return slices.ContainsFunc(im.Tags, func(t necos.Tag) bool { return t.ID == id })
+			})
+	case necos.Artists:
+		s.serveList(w, query, filter(s.Data.Artists, query, artistMatches))
+	case necos.ArtistByID:
+		writeOne(w, find(s.Data.Artists, func(a necos.Artist) bool { return a.ID == id }))
+	case necos.ArtistImages:
+		s.serveImagesOf(w, query, find(s.Data.Artists, func(a necos.Artist) bool { return a.ID == id }) != nil,
+			func(im necos.Image) bool { return im.Artist.ID == id })
+	case necos.Characters:
+		s.serveList(w, query, filter(s.Data.Characters, query, characterMatches))
+	case necos.CharacterByID:
+		writeOne(w, find(s.Data.Characters, func(c necos.Character) bool { return c.ID == id }))
+	case necos.CharacterImages:
+		s.serveImagesOf(w, query, find(s.Data.Characters, func(c necos.Character) bool { return c.ID == id }) != nil,
+			func(im necos.Image) bool {
+				return slices.ContainsFunc(im.Characters, func(c necos.Character) bool { return c.ID == id })
+			})
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	content, ok := s.Data.Files[r.URL.Path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, _ = w.Write(content)
+}
+
+func (s *Server) serveRandom(w http.ResponseWriter, query url.Values) {
+	limit, ok := parseLimit(w, query)
+	if !ok {
+		return
+	}
+
+	images := filter(s.Data.Images, query, imageMatches)
+	s.mu.Lock()
+	s.random.Shuffle(len(images), func(i, j int) {
+		images[i], images[j] = images[j], images[i]
+	})
+	s.mu.Unlock()
+
+	images = images[:min(limit, len(images))]
+	writeJSON(w, necos.MultipleContainer[necos.Image]{Items: s.absolute(images), Count: len(images)})
+}
+
+func (s *Server) serveRandomFile(w http.ResponseWriter, r *http.Request) {
+	images := filter(s.Data.Images, r.URL.Query(), imageMatches)
+	if len(images) == 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	s.mu.Lock()
+	im := images[s.random.IntN(len(images))]
+	s.mu.Unlock()
+
+	http.Redirect(w, r, s.URL+im.ImageURL, http.StatusFound)
+}
+
+// serveImagesOf serves images matching the owner (tag, artist or character), 404 if owner doesn't exist
+func (s *Server) serveImagesOf(w http.ResponseWriter, query url.Values, exists bool, belongs func(necos.Image) bool) {
+	if !exists {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var images []necos.Image
+	for _, im := range s.Data.Images {
+		if belongs(im) {
+			images = append(images, im)
+		}
+	}
+	s.serveList(w, query, s.absolute(images))
+}
+
+func (s *Server) findImage(id int) *necos.Image {
+	im := find(s.Data.Images, func(im necos.Image) bool { return im.ID == id })
+	if im == nil {
+		return nil
+	}
+
+	abs := s.absolute([]necos.Image{*im})[0]
+	return &abs
+}
+
+// absolute returns copy of images with absolute urls
+func (s *Server) absolute(images []necos.Image) []necos.Image {
+	res := make([]necos.Image, len(images))
+	for i, im := range images {
+		if strings.HasPrefix(im.ImageURL, "/") {
+			im.ImageURL = s.URL + im.ImageURL
+		}
+		if strings.HasPrefix(im.SampleURL, "/") {
+			im.SampleURL = s.URL + im.SampleURL
+		}
+		res[i] = im
+	}
+	return res
+}
+
+// writeOne writes item or 404 if it's nil
+func writeOne[T any](w http.ResponseWriter, item *T) {
+	if item == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, item)
+}
+
+// serveList writes page of items selected by limit and offset
+func (s *Server) serveList(w http.ResponseWriter, query url.Values, items any) {
+	limit, ok := parseLimit(w, query)
+	if !ok {
+		return
+	}
+
+	offset := 0
+	if value := query.Get("offset"); value != "" {
+		var err error
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			writeValidationError(w, "offset", "Input should be greater than or equal to 0")
+			return
+		}
+	}
+
+	switch items := items.(type) {
+	case []necos.Image:
+		writeJSON(w, page(items, limit, offset))
+	case []necos.Tag:
+		writeJSON(w, page(items, limit, offset))
+	case []necos.Artist:
+		writeJSON(w, page(items, limit, offset))
+	case []necos.Character:
+		writeJSON(w, page(items, limit, offset))
+	default:
+		panic(fmt.Sprintf("necostest: unexpected list %T", items))
+	}
+}
+
+func page[T any](items []T, limit, offset int) necos.MultipleContainer[T] {
+	offset = min(offset, len(items))
+	end := min(offset+limit, len(items))
+	return necos.MultipleContainer[T]{Items: append([]T{}, items[offset:end]...), Count: len(items)}
+}
+
+// parseLimit parses limit of the query, writing validation error if it's wrong
+func parseLimit(w http.ResponseWriter, query url.Values) (int, bool) {
+	value := query.Get("limit")
+	if value == "" {
+		return necos.MaxPageSize, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > necos.MaxPageSize {
+		writeValidationError(w, "limit", fmt.Sprintf("Input should be in [1..%d]", necos.MaxPageSize))
+		return 0, false
+	}
+	return limit, true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
+
+func writeValidationError(w http.ResponseWriter, param, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"detail": []necos.ValidationError{{Loc: []any{"query", param}, Msg: msg, Type: "value_error"}},
+	})
+}
+
+// templateOf turns path into endpoint template by replacing numeric segments with %d
+func templateOf(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if _, err := strconv.Atoi(s); err == nil {
+			segments[i] = "%d"
+		}
+	}
+	return strings.Join(segments, "/")
+}
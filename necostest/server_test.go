@@ -0,0 +1,174 @@
+package necostest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/rinnothing/go-necos"
+	"github.com/stretchr/testify/require"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+func TestDataset(t *testing.T) {
+	a := NewDataset(42, DefaultSizes)
+	b := NewDataset(42, DefaultSizes)
+	require.Equal(t, a, b)
+	require.Len(t, a.Images, DefaultSizes.Images)
+
+	c := NewDataset(43, DefaultSizes)
+	require.NotEqual(t, a.Images, c.Images)
+}
+
+func TestServerEndpoints(t *testing.T) {
+	s := NewServer(1)
+	defer s.Close()
+	c := s.Client()
+
+	images, err := c.GetImages(necos.OneValue())
+	require.NoError(t, err)
+	require.Len(t, images.Items, 1)
+	require.Equal(t, DefaultSizes.Images, images.Count)
+
+	safe, err := c.GetImages(necos.SafeRequest())
+	require.NoError(t, err)
+	require.NotEmpty(t, safe.Items)
+	for _, im := range safe.Items {
+		require.Equal(t, necos.RatingSafe, im.Rating)
+	}
+
+	random, err := c.GetRandomImages(necos.AddFields(nil, "limit", 5))
+	require.NoError(t, err)
+	require.Len(t, random.Items, 5)
+
+	im, err := c.GetImageByID(images.Items[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, images.Items[0], im)
+
+	artist, err := c.GetImageArtist(im.ID)
+	require.NoError(t, err)
+	require.Equal(t, im.Artist, artist)
+
+	imageTags, err := c.GetImageTags(im.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, im.Tags, imageTags.Items)
+
+	imageCharacters, err := c.GetImageCharacters(im.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, im.Characters, imageCharacters.Items)
+
+	tag := im.Tags[0]
+	found, err := c.GetTagByID(tag.ID)
+	require.NoError(t, err)
+	require.Equal(t, tag, found)
+
+	tags, err := c.GetTags(necos.AddFields(nil, "search", tag.Name))
+	require.NoError(t, err)
+	require.Contains(t, tags.Items, tag)
+
+	tagImages, err := c.GetTagImages(tag.ID, nil)
+	require.NoError(t, err)
+	for _, im := range tagImages.Items {
+		require.Contains(t, im.Tags, tag)
+	}
+
+	foundArtist, err := c.GetArtistByID(artist.ID)
+	require.NoError(t, err)
+	require.Equal(t, artist, foundArtist)
+
+	artists, err := c.GetArtists(nil)
+	require.NoError(t, err)
+	require.Equal(t, DefaultSizes.Artists, artists.Count)
+
+	artistImages, err := c.GetArtistImages(artist.ID, nil)
+	require.NoError(t, err)
+	for _, im := range artistImages.Items {
+		require.Equal(t, artist.ID, im.Artist.ID)
+	}
+
+	character := im.Characters[0]
+	foundCharacter, err := c.GetCharacterByID(character.ID)
+	require.NoError(t, err)
+	require.Equal(t, character, foundCharacter)
+
+	characters, err := c.GetCharacters(necos.AddFields(nil, "gender", character.Gender))
+	require.NoError(t, err)
+	require.Contains(t, characters.Items, character)
+
+	characterImages, err := c.GetCharacterImages(character.ID, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, characterImages.Items)
+
+	require.NoError(t, c.PostReport(necos.AddFields(nil, "id", im.ID)))
+	require.Equal(t, []necos.Report{{"id": {"1"}}}, s.Reports())
+
+	_, err = c.GetTagByID(100500)
+	require.True(t, necos.IsNotFound(err))
+}
+
+func TestServerPagination(t *testing.T) {
+	s := NewServer(2)
+	defer s.Close()
+	c := s.Client()
+
+	var ids []int
+	for im, err := range c.AllImages(context.Background(), nil, necos.PageSize(30)) {
+		require.NoError(t, err)
+		ids = append(ids, im.ID)
+	}
+	require.Len(t, ids, DefaultSizes.Images)
+	require.Equal(t, DefaultSizes.Images, ids[len(ids)-1])
+
+	_, err := c.GetImages(necos.AddFields(nil, "limit", 500))
+	require.True(t, necos.IsClientError(err))
+}
+
+func TestServerDownload(t *testing.T) {
+	s := NewServer(3)
+	defer s.Close()
+	c := s.Client()
+
+	images, err := c.GetImages(necos.OneValue())
+	require.NoError(t, err)
+	im := images.Items[0]
+
+	var content []byte
+	require.NoError(t, c.DownloadImage(&im, necos.SaveToSlice(&content)))
+	sum := md5.Sum(content)
+	require.Equal(t, im.HashMD5, hex.EncodeToString(sum[:]))
+
+	_, err = png.Decode(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, c.GetRandomImageFileWithContext(context.Background(), nil, &file))
+	_, err = png.Decode(&file)
+	require.NoError(t, err)
+}
+
+func TestServerFail(t *testing.T) {
+	s := NewServer(4)
+	defer s.Close()
+	c := s.Client()
+
+	s.Fail(necos.TagByID, http.StatusInternalServerError, 2)
+
+	_, err := c.GetTagByID(1)
+	require.True(t, necos.IsServerError(err))
+
+	c.Retry = necos.DefaultRetryPolicy()
+	c.Retry.BaseDelay = 0
+	_, err = c.GetTagByID(2)
+	require.NoError(t, err)
+
+	s.Fail(necos.Images, http.StatusTooManyRequests, -1)
+	_, err = c.GetImages(nil)
+	require.True(t, necos.IsRateLimited(err))
+
+	s.Reset()
+	_, err = c.GetImages(nil)
+	require.NoError(t, err)
+	require.Len(t, s.Requests(), 1)
+}
@@ -0,0 +1,160 @@
+package necos
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Option configures Client created by NewClient
+type Option func(*Client)
+
+// WithDomain sets API domain used instead of DefaultDomain
+func WithDomain(domain string) Option {
+	return func(c *Client) {
+		c.Domain = domain
+	}
+}
+
+// WithDefaultQuery sets parameters added to every call that doesn't have them
+//
+// query is copied, so changing it afterwards doesn't affect Client
+func WithDefaultQuery(query url.Values) Option {
+	return func(c *Client) {
+		if query == nil {
+			c.DefaultQuery = nil
+			return
+		}
+
+		c.DefaultQuery = make(url.Values, len(query))
+		for key, values := range query {
+			c.DefaultQuery[key] = slices.Clone(values)
+		}
+	}
+}
+
+// WithTimeout sets time limit for every request made by Client (including reading of response body)
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.Timeout = timeout
+	}
+}
+
+// WithTransport sets http.RoundTripper used to make requests
+//
+// WithProxy and WithTLSConfig only configure *http.Transport (a clone of it), so they should go after it
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.Transport = transport
+	}
+}
+
+// WithProxy makes requests go through proxy with given url
+//
+// it modifies a clone of *http.Transport of the Client (http.DefaultTransport by default),
+// with any other http.RoundTripper set by WithTransport it does nothing
+func WithProxy(proxy *url.URL) Option {
+	return func(c *Client) {
+		if t := c.httpTransport(); t != nil {
+			t.Proxy = http.ProxyURL(proxy)
+		}
+	}
+}
+
+// WithTLSConfig sets TLS configuration used for requests
+//
+// like WithProxy, it modifies a clone of *http.Transport of the Client
+// and does nothing with any other http.RoundTripper set by WithTransport
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		if t := c.httpTransport(); t != nil {
+			t.TLSClientConfig = config
+		}
+	}
+}
+
+// WithUserAgent sets User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithHeader adds header sent with every request
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.Header == nil {
+			c.Header = make(http.Header)
+		}
+		c.Header.Add(key, value)
+	}
+}
+
+// WithRetry sets the policy used to retry failed requests
+func WithRetry(policy *RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = policy
+	}
+}
+
+// WithRateLimit limits API calls to rps per second with bursts up to burst calls
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.Limiter = NewRateLimiter(rps, burst)
+	}
+}
+
+// WithDownloadRateLimit limits image downloads to rps per second with bursts up to burst downloads
+func WithDownloadRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.DownloadLimiter = NewRateLimiter(rps, burst)
+	}
+}
+
+// WithCache makes GET responses stored in cache for ttl (unless CacheTTL or Cache-Control says otherwise)
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.Cache = cache
+		c.DefaultCacheTTL = ttl
+	}
+}
+
+// WithLogger makes Client write a record about every API call and download to logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.Logger = logger
+	}
+}
+
+// WithMetrics makes Client report measurements of every API call and download to metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.Metrics = metrics
+	}
+}
+
+// WithTracer makes Client trace every API call and download with tracer
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.Tracer = tracer
+	}
+}
+
+// httpTransport replaces Transport of the Client with a clone, which can be modified, and returns it
+//
+// the transport is cloned even if it's *http.Transport given by user, since it may be shared with other clients,
+// nil is returned if Transport is some other http.RoundTripper
+func (c *Client) httpTransport() *http.Transport {
+	var t *http.Transport
+	switch transport := c.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = transport.Clone()
+	default:
+		return nil
+	}
+	c.Transport = t
+	return t
+}
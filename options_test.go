@@ -0,0 +1,113 @@
+package necos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient()
+	require.Equal(t, DefaultDomain, c.Domain)
+	require.Nil(t, c.Transport)
+	require.Nil(t, c.Header)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	var got *http.Request
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte(`"ok"`))
+	}))
+	defer s.Close()
+
+	policy := DefaultRetryPolicy()
+	query := url.Values{"limit": {"5"}}
+	c := NewClient(
+		WithDomain(s.URL),
+		WithDefaultQuery(query),
+		WithTimeout(time.Second),
+		WithUserAgent("necos-test/1.0"),
+		WithHeader("X-Token", "abc"),
+		WithRetry(policy),
+		WithRateLimit(10, 2),
+	)
+	require.Equal(t, time.Second, c.Timeout)
+	require.Same(t, policy, c.Retry)
+	require.NotNil(t, c.Limiter)
+
+	// the query is copied, so changes of caller's map don't leak into Client
+	query["limit"][0] = "10"
+	query.Set("offset", "1")
+
+	var result string
+	require.NoError(t, c.Get("/tags", nil, &result))
+	require.Equal(t, "ok", result)
+	require.Equal(t, "necos-test/1.0", got.UserAgent())
+	require.Equal(t, "abc", got.Header.Get("X-Token"))
+	require.Equal(t, "limit=5", got.URL.RawQuery)
+}
+
+func TestWithProxy(t *testing.T) {
+	var got *http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte(`"proxied"`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	c := NewClient(WithDomain("http://nekos.invalid"), WithProxy(proxyURL))
+
+	var result string
+	require.NoError(t, c.Get("/tags", nil, &result))
+	require.Equal(t, "proxied", result)
+	require.Equal(t, "nekos.invalid", got.Host)
+
+	// the default transport isn't touched
+	require.NotSame(t, http.DefaultTransport, c.Transport)
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"secure"`))
+	}))
+	defer s.Close()
+
+	var result string
+	require.Error(t, NewClient(WithDomain(s.URL)).Get("/", nil, &result))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(s.Certificate())
+	c := NewClient(WithDomain(s.URL), WithTLSConfig(&tls.Config{RootCAs: pool}))
+	require.NoError(t, c.Get("/", nil, &result))
+	require.Equal(t, "secure", result)
+
+	// user's *http.Transport may be shared, so it's cloned
+	shared := &http.Transport{MaxIdleConnsPerHost: 7}
+	c = NewClient(WithTransport(shared), WithTLSConfig(&tls.Config{RootCAs: pool}), WithProxy(nil))
+	require.Nil(t, shared.Proxy)
+	// Clone sets up HTTP/2 in the original, which may create its TLSClientConfig
+	require.True(t, shared.TLSClientConfig == nil || shared.TLSClientConfig.RootCAs == nil)
+	require.NotSame(t, shared, c.Transport)
+	require.Equal(t, 7, c.Transport.(*http.Transport).MaxIdleConnsPerHost)
+	require.Same(t, pool, c.Transport.(*http.Transport).TLSClientConfig.RootCAs)
+
+	// custom transports are left as they are
+	transport := roundTripperFunc(http.DefaultTransport.RoundTrip)
+	c = NewClient(WithTransport(transport), WithTLSConfig(&tls.Config{RootCAs: pool}))
+	require.IsType(t, transport, c.Transport)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,181 @@
+package necos
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"strconv"
+)
+
+// MaxPageSize is the maximum limit accepted by list endpoints
+const MaxPageSize = 100
+
+// PageOption configures iterators walking through list endpoints
+type PageOption func(*pageConfig)
+
+type pageConfig struct {
+	pageSize    int
+	maxItems    int
+	stopOnError bool
+}
+
+func newPageConfig(opts []PageOption) pageConfig {
+	cfg := pageConfig{
+		pageSize:    MaxPageSize,
+		stopOnError: true,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// PageSize sets the limit used for each page request, it's clamped to [1..100]
+func PageSize(n int) PageOption {
+	return func(cfg *pageConfig) {
+		cfg.pageSize = min(max(n, 1), MaxPageSize)
+	}
+}
+
+// MaxItems stops the iteration after n items, n <= 0 means no limit
+func MaxItems(n int) PageOption {
+	return func(cfg *pageConfig) {
+		cfg.maxItems = n
+	}
+}
+
+// StopOnError sets whether iteration ends after the first failed page (the default)
+//
+// when it's false failed page is yielded as error and skipped,
+// but only if the total count is already known, otherwise iteration still ends
+func StopOnError(stop bool) PageOption {
+	return func(cfg *pageConfig) {
+		cfg.stopOnError = stop
+	}
+}
+
+// paginate walks through pages returned by fetch moving offset until Count items are seen
+//
+// limit and offset of req are overwritten, though offset of req is used as the starting one
+func paginate[T any](ctx context.Context, req Request, opts []PageOption,
+	fetch func(context.Context, Request) (MultipleContainer[T], error)) iter.Seq2[T, error] {
+	cfg := newPageConfig(opts)
+
+	return func(yield func(T, error) bool) {
+		query := maps.Clone(req)
+		if query == nil {
+			query = Request{}
+		}
+
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		count, yielded := -1, 0
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			limit := cfg.pageSize
+			if cfg.maxItems > 0 {
+				limit = min(limit, cfg.maxItems-yielded)
+			}
+			query.Set("limit", strconv.Itoa(limit))
+			query.Set("offset", strconv.Itoa(offset))
+
+			page, err := fetch(ctx, query)
+			if err != nil {
+				var zero T
+				if !yield(zero, err) || cfg.stopOnError || count < 0 {
+					return
+				}
+
+				offset += limit
+				if offset >= count {
+					return
+				}
+				continue
+			}
+
+			count = page.Count
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+
+				yielded++
+				if cfg.maxItems > 0 && yielded >= cfg.maxItems {
+					return
+				}
+			}
+
+			offset += len(page.Items)
+			if len(page.Items) == 0 || offset >= count {
+				return
+			}
+		}
+	}
+}
+
+// AllImages iterates over all the images returned by Images endpoint
+//
+// For more info on Request parameters see GetImages
+func (c *Client) AllImages(ctx context.Context, req Request, opts ...PageOption) iter.Seq2[Image, error] {
+	return paginate(ctx, req, opts, c.GetImagesWithContext)
+}
+
+// AllTags iterates over all the tags returned by Tags endpoint
+//
+// For more info on Request parameters see GetTags
+func (c *Client) AllTags(ctx context.Context, req Request, opts ...PageOption) iter.Seq2[Tag, error] {
+	return paginate(ctx, req, opts, c.GetTagsWithContext)
+}
+
+// AllTagImages iterates over all the images returned by TagImages endpoint
+func (c *Client) AllTagImages(ctx context.Context, tagID int, req Request, opts ...PageOption) iter.Seq2[Image, error] {
+	return paginate(ctx, req, opts, func(ctx context.Context, req Request) (MultipleContainer[Image], error) {
+		return c.GetTagImagesWithContext(ctx, tagID, req)
+	})
+}
+
+// AllImageCharacters iterates over all the characters returned by ImageCharacters endpoint
+func (c *Client) AllImageCharacters(ctx context.Context, id int, req Request, opts ...PageOption) iter.Seq2[Character, error] {
+	return paginate(ctx, req, opts, func(ctx context.Context, req Request) (MultipleContainer[Character], error) {
+		return c.GetImageCharactersWithContext(ctx, id, req)
+	})
+}
+
+// AllImageTags iterates over all the tags returned by ImageTags endpoint
+func (c *Client) AllImageTags(ctx context.Context, id int, req Request, opts ...PageOption) iter.Seq2[Tag, error] {
+	return paginate(ctx, req, opts, func(ctx context.Context, req Request) (MultipleContainer[Tag], error) {
+		return c.GetImageTagsWithContext(ctx, id, req)
+	})
+}
+
+// AllArtists iterates over all the artists returned by Artists endpoint
+//
+// For more info on Request parameters see GetArtists
+func (c *Client) AllArtists(ctx context.Context, req Request, opts ...PageOption) iter.Seq2[Artist, error] {
+	return paginate(ctx, req, opts, c.GetArtistsWithContext)
+}
+
+// AllArtistImages iterates over all the images returned by ArtistImages endpoint
+func (c *Client) AllArtistImages(ctx context.Context, id int, req Request, opts ...PageOption) iter.Seq2[Image, error] {
+	return paginate(ctx, req, opts, func(ctx context.Context, req Request) (MultipleContainer[Image], error) {
+		return c.GetArtistImagesWithContext(ctx, id, req)
+	})
+}
+
+// AllCharacters iterates over all the characters returned by Characters endpoint
+//
+// For more info on Request parameters see GetCharacters
+func (c *Client) AllCharacters(ctx context.Context, req Request, opts ...PageOption) iter.Seq2[Character, error] {
+	return paginate(ctx, req, opts, c.GetCharactersWithContext)
+}
+
+// AllCharacterImages iterates over all the images returned by CharacterImages endpoint
+func (c *Client) AllCharacterImages(ctx context.Context, id int, req Request, opts ...PageOption) iter.Seq2[Image, error] {
+	return paginate(ctx, req, opts, func(ctx context.Context, req Request) (MultipleContainer[Image], error) {
+		return c.GetCharacterImagesWithContext(ctx, id, req)
+	})
+}
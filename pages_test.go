@@ -0,0 +1,127 @@
+package necos
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// pagedServer serves count tags by limit and offset, failing the page with failOffset
+func pagedServer(count, failOffset int, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if offset == failOffset {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		page := MultipleContainer[Tag]{Count: count, Items: []Tag{}}
+		for i := offset; i < min(offset+limit, count); i++ {
+			page.Items = append(page.Items, Tag{ID: i})
+		}
+		m, _ := json.Marshal(page)
+		_, _ = w.Write(m)
+	}))
+}
+
+func collectTags(t *testing.T, c *Client, req Request, opts ...PageOption) ([]int, []error) {
+	t.Helper()
+
+	var ids []int
+	var errs []error
+	for tag, err := range c.AllTags(context.Background(), req, opts...) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, tag.ID)
+	}
+	return ids, errs
+}
+
+func TestAllTags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all", func(t *testing.T) {
+		var calls atomic.Int32
+		s := pagedServer(250, -1, &calls)
+		defer s.Close()
+
+		ids, errs := collectTags(t, &Client{Domain: s.URL}, nil)
+		require.Empty(t, errs)
+		require.Len(t, ids, 250)
+		require.Equal(t, 249, ids[249])
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("page_size_and_offset", func(t *testing.T) {
+		var calls atomic.Int32
+		s := pagedServer(25, -1, &calls)
+		defer s.Close()
+
+		ids, errs := collectTags(t, &Client{Domain: s.URL}, Request{"offset": {"5"}}, PageSize(10))
+		require.Empty(t, errs)
+		require.Len(t, ids, 20)
+		require.Equal(t, 5, ids[0])
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("max_items", func(t *testing.T) {
+		var calls atomic.Int32
+		s := pagedServer(250, -1, &calls)
+		defer s.Close()
+
+		ids, errs := collectTags(t, &Client{Domain: s.URL}, nil, MaxItems(130))
+		require.Empty(t, errs)
+		require.Len(t, ids, 130)
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("stop_on_error", func(t *testing.T) {
+		var calls atomic.Int32
+		s := pagedServer(30, 10, &calls)
+		defer s.Close()
+
+		ids, errs := collectTags(t, &Client{Domain: s.URL}, nil, PageSize(10))
+		require.Len(t, errs, 1)
+		require.True(t, IsServerError(errs[0]))
+		require.Len(t, ids, 10)
+	})
+
+	t.Run("continue_on_error", func(t *testing.T) {
+		var calls atomic.Int32
+		s := pagedServer(30, 10, &calls)
+		defer s.Close()
+
+		ids, errs := collectTags(t, &Client{Domain: s.URL}, nil, PageSize(10), StopOnError(false))
+		require.Len(t, errs, 1)
+		require.Len(t, ids, 20)
+		require.Equal(t, 20, ids[10])
+	})
+
+	t.Run("break", func(t *testing.T) {
+		var calls atomic.Int32
+		s := pagedServer(250, -1, &calls)
+		defer s.Close()
+
+		c := &Client{Domain: s.URL}
+		seen := 0
+		for _, err := range c.AllTags(context.Background(), nil) {
+			require.NoError(t, err)
+			seen++
+			if seen == 3 {
+				break
+			}
+		}
+		require.Equal(t, 3, seen)
+		require.Equal(t, int32(1), calls.Load())
+	})
+}
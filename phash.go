@@ -0,0 +1,258 @@
+package necos
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+	"strconv"
+)
+
+// DefaultDuplicateThreshold is the Hamming distance under which images are usually the same picture
+const DefaultDuplicateThreshold = 10
+
+// PerceptualHash is 64-bit perceptual hash of an image, similar images have hashes differing in few bits
+type PerceptualHash uint64
+
+// ParsePerceptualHash parses hash in the form API gives it (16 hex digits)
+func ParsePerceptualHash(s string) (PerceptualHash, error) {
+	value, err := strconv.ParseUint(s, 16, 64)
+	if len(s) != 16 || err != nil {
+		return 0, fmt.Errorf("necos: invalid perceptual hash %q", s)
+	}
+	return PerceptualHash(value), nil
+}
+
+func (h PerceptualHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Distance returns Hamming distance between hashes, in [0..64]
+func (h PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// PerceptualHash parses HashPerceptual of the image
+func (im Image) PerceptualHash() (PerceptualHash, error) {
+	return ParsePerceptualHash(im.HashPerceptual)
+}
+
+// HashDistance returns Hamming distance between perceptual hashes of images
+func HashDistance(a, b Image) (int, error) {
+	ha, err := a.PerceptualHash()
+	if err != nil {
+		return 0, err
+	}
+	hb, err := b.PerceptualHash()
+	if err != nil {
+		return 0, err
+	}
+	return ha.Distance(hb), nil
+}
+
+// HashMatch is an image found in HashIndex
+type HashMatch struct {
+	Image    Image
+	Distance int
+}
+
+// HashIndex is a BK-tree of images by their perceptual hashes,
+// it finds images within given distance without comparing with every image
+//
+// HashIndex isn't safe for concurrent use
+type HashIndex struct {
+	root *hashNode
+	size int
+}
+
+type hashNode struct {
+	hash     PerceptualHash
+	images   []Image
+	children map[int]*hashNode
+}
+
+// NewHashIndex creates empty HashIndex
+func NewHashIndex() *HashIndex {
+	return &HashIndex{}
+}
+
+// Add adds image to the index, it fails if image has invalid HashPerceptual
+func (x *HashIndex) Add(im Image) error {
+	h, err := im.PerceptualHash()
+	if err != nil {
+		return err
+	}
+	x.add(h, im)
+	return nil
+}
+
+func (x *HashIndex) add(h PerceptualHash, im Image) {
+	x.size++
+	if x.root == nil {
+		x.root = &hashNode{hash: h, images: []Image{im}}
+		return
+	}
+
+	node := x.root
+	for {
+		d := node.hash.Distance(h)
+		if d == 0 {
+			node.images = append(node.images, im)
+			return
+		}
+
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*hashNode)
+			}
+			node.children[d] = &hashNode{hash: h, images: []Image{im}}
+			return
+		}
+		node = child
+	}
+}
+
+// Len returns the number of images in the index
+func (x *HashIndex) Len() int {
+	return x.size
+}
+
+// Search returns images with hashes within threshold of h, from the closest to the farthest
+func (x *HashIndex) Search(h PerceptualHash, threshold int) []HashMatch {
+	var matches []HashMatch
+	if x.root == nil {
+		return matches
+	}
+
+	stack := []*hashNode{x.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := node.hash.Distance(h)
+		if d <= threshold {
+			for _, im := range node.images {
+				matches = append(matches, HashMatch{Image: im, Distance: d})
+			}
+		}
+
+		// by triangle inequality only children at distance in [d-threshold..d+threshold] may match
+		for cd, child := range node.children {
+			if cd >= d-threshold && cd <= d+threshold {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b HashMatch) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Image.ID, b.Image.ID))
+	})
+	return matches
+}
+
+// SearchImage is like Search but takes hash of the image, it fails if image has invalid HashPerceptual
+func (x *HashIndex) SearchImage(im Image, threshold int) ([]HashMatch, error) {
+	h, err := im.PerceptualHash()
+	if err != nil {
+		return nil, err
+	}
+	return x.Search(h, threshold), nil
+}
+
+// Dedupe groups near-duplicate images, whose perceptual hashes are within threshold of each other
+//
+// grouping is transitive: if a is close to b and b is close to c, all of them are in one group.
+// Groups and images in them keep the order of images, so taking the first image of each group gives unique images.
+// Images with the same non-zero ID are kept only once and images with invalid HashPerceptual get groups of their own
+func Dedupe(images []Image, threshold int) [][]Image {
+	groups, _ := DedupeSeq(func(yield func(Image, error) bool) {
+		for _, im := range images {
+			if !yield(im, nil) {
+				return
+			}
+		}
+	}, threshold)
+	return groups
+}
+
+// DedupeSeq groups images of seq like Dedupe does
+//
+// it's meant to be used with iterators like AllImages, on error it returns groups found so far with the error
+func DedupeSeq(seq iter.Seq2[Image, error], threshold int) ([][]Image, error) {
+	var d deduper
+	for im, err := range seq {
+		if err != nil {
+			return d.groups(), err
+		}
+		d.add(im, threshold)
+	}
+	return d.groups(), nil
+}
+
+// deduper groups images with union-find over their positions
+type deduper struct {
+	index   HashIndex
+	images  []Image
+	parents []int
+	seen    map[int]bool
+}
+
+func (d *deduper) add(im Image, threshold int) {
+	// images without ID (e.g. made locally) can't be told apart by it
+	if im.ID != 0 {
+		if d.seen[im.ID] {
+			return
+		}
+		if d.seen == nil {
+			d.seen = make(map[int]bool)
+		}
+		d.seen[im.ID] = true
+	}
+
+	pos := len(d.images)
+	d.images = append(d.images, im)
+	d.parents = append(d.parents, pos)
+
+	h, err := im.PerceptualHash()
+	if err != nil {
+		return
+	}
+
+	// index stores positions in ID field of copies, so they can be found in d.images
+	for _, match := range d.index.Search(h, threshold) {
+		d.union(match.Image.ID, pos)
+	}
+	d.index.add(h, Image{ID: pos})
+}
+
+func (d *deduper) find(pos int) int {
+	for d.parents[pos] != pos {
+		d.parents[pos] = d.parents[d.parents[pos]]
+		pos = d.parents[pos]
+	}
+	return pos
+}
+
+func (d *deduper) union(a, b int) {
+	a, b = d.find(a), d.find(b)
+	// the earliest image stays the root, so groups keep the order of images
+	d.parents[max(a, b)] = min(a, b)
+}
+
+func (d *deduper) groups() [][]Image {
+	var groups [][]Image
+	byRoot := make(map[int]int)
+	for pos, im := range d.images {
+		root := d.find(pos)
+		i, ok := byRoot[root]
+		if !ok {
+			i = len(groups)
+			byRoot[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], im)
+	}
+	return groups
+}
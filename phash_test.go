@@ -0,0 +1,121 @@
+package necos
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math/rand/v2"
+	"testing"
+)
+
+func hashImage(id int, hash uint64) Image {
+	return Image{ID: id, HashPerceptual: fmt.Sprintf("%016x", hash)}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	h, err := ParsePerceptualHash("c3d1e0f0f8f8e0c1")
+	require.NoError(t, err)
+	require.Equal(t, PerceptualHash(0xc3d1e0f0f8f8e0c1), h)
+	require.Equal(t, "c3d1e0f0f8f8e0c1", h.String())
+
+	for _, s := range []string{"", "c3d1", "c3d1e0f0f8f8e0c1f", "g3d1e0f0f8f8e0c1", "+3d1e0f0f8f8e0c1"} {
+		_, err = ParsePerceptualHash(s)
+		require.Error(t, err, s)
+	}
+
+	require.Equal(t, 0, h.Distance(h))
+	require.Equal(t, 64, h.Distance(^h))
+	require.Equal(t, 3, PerceptualHash(0b1011).Distance(0))
+
+	d, err := HashDistance(hashImage(1, 0xff), hashImage(2, 0x0f))
+	require.NoError(t, err)
+	require.Equal(t, 4, d)
+	_, err = HashDistance(hashImage(1, 0), Image{ID: 2})
+	require.Error(t, err)
+}
+
+func TestHashIndex(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	x := NewHashIndex()
+	var images []Image
+	for i := range 2000 {
+		im := hashImage(i, r.Uint64())
+		images = append(images, im)
+		require.NoError(t, x.Add(im))
+	}
+	// the same hash twice
+	require.NoError(t, x.Add(hashImage(2000, 0)))
+	require.NoError(t, x.Add(hashImage(2001, 0)))
+	images = append(images, hashImage(2000, 0), hashImage(2001, 0))
+	require.Error(t, x.Add(Image{HashPerceptual: "bad"}))
+	require.Equal(t, 2002, x.Len())
+
+	for _, threshold := range []int{0, 5, 20, 28} {
+		query := PerceptualHash(r.Uint64())
+		if threshold == 0 {
+			query = 0
+		}
+
+		var want []int
+		for _, im := range images {
+			h, _ := im.PerceptualHash()
+			if h.Distance(query) <= threshold {
+				want = append(want, im.ID)
+			}
+		}
+
+		matches := x.Search(query, threshold)
+		var got []int
+		for i, m := range matches {
+			got = append(got, m.Image.ID)
+			if i > 0 {
+				require.LessOrEqual(t, matches[i-1].Distance, m.Distance)
+			}
+		}
+		require.ElementsMatch(t, want, got, threshold)
+	}
+
+	matches, err := x.SearchImage(hashImage(0, 1), 0)
+	require.NoError(t, err)
+	require.Empty(t, matches)
+	require.Empty(t, NewHashIndex().Search(0, 64))
+}
+
+func TestDedupe(t *testing.T) {
+	images := []Image{
+		hashImage(1, 0x0000),
+		hashImage(2, 0xffff_0000_0000_0000),
+		hashImage(3, 0x0003),              // 2 bits from 1
+		hashImage(4, 0x000f),              // 2 bits from 3, 4 bits from 1
+		{ID: 5, HashPerceptual: "broken"}, // own group
+		hashImage(6, 0xffff_0000_0000_0001),
+		hashImage(1, 0xffff), // the same ID again
+		{ID: 7},
+	}
+
+	groups := Dedupe(images, 2)
+	var ids [][]int
+	for _, g := range groups {
+		ids = append(ids, imageIDs(g))
+	}
+	require.Equal(t, [][]int{{1, 3, 4}, {2, 6}, {5}, {7}}, ids)
+
+	seq := func(yield func(Image, error) bool) {
+		for _, im := range images[:3] {
+			if !yield(im, nil) {
+				return
+			}
+		}
+		yield(Image{}, BadStatusError)
+	}
+	groups, err := DedupeSeq(seq, 0)
+	require.ErrorIs(t, err, BadStatusError)
+	require.Len(t, groups, 3)
+
+	require.Empty(t, Dedupe(nil, DefaultDuplicateThreshold))
+
+	// images without IDs are all kept
+	groups = Dedupe([]Image{hashImage(0, 0), hashImage(0, ^uint64(0)), hashImage(0, 1)}, 2)
+	require.Len(t, groups, 2)
+	require.Len(t, groups[0], 2)
+	require.Equal(t, "ffffffffffffffff", groups[1][0].HashPerceptual)
+}
@@ -0,0 +1,215 @@
+package necos
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// InvalidQueryError is returned by Validate when query has values API won't accept
+var InvalidQueryError = errors.New("invalid query")
+
+// Bool returns pointer to b, it's handy to fill optional boolean fields of queries
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Page contains pagination parameters of list endpoints, zero values aren't sent
+//
+// on its own it can be used for endpoints like TagImages, ImageTags or ArtistImages
+type Page struct {
+	// Limit is [1..100], 100 by default
+	Limit int
+	// Offset is >= 0, 0 by default
+	Offset int
+}
+
+// Validate checks that Limit and Offset are in documented ranges
+func (p Page) Validate() error {
+	if p.Limit < 0 || p.Limit > MaxPageSize {
+		return fmt.Errorf("%w: limit must be in [1..%d], got %d", InvalidQueryError, MaxPageSize, p.Limit)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: offset must be >= 0, got %d", InvalidQueryError, p.Offset)
+	}
+	return nil
+}
+
+// Encode makes Request out of Page
+func (p Page) Encode() Request {
+	r := Request{}
+	p.encode(r)
+	return r
+}
+
+func (p Page) encode(r Request) {
+	setInt(r, "limit", p.Limit)
+	setInt(r, "offset", p.Offset)
+}
+
+// ImageQuery is a typed Request for Images, RandomImages and other endpoints returning images
+//
+// Offset isn't supported by RandomImages
+type ImageQuery struct {
+	Rating       []Rating
+	IsOriginal   *bool
+	IsScreenshot *bool
+	IsFlagged    *bool
+	IsAnimated   *bool
+	// Artist is the artist's ID, 0 means any
+	Artist int
+	// Characters are the characters' IDs
+	Characters []int
+	// Tags are the tags' IDs
+	Tags []int
+	Page
+}
+
+// Validate checks that query values are in documented ranges
+func (q *ImageQuery) Validate() error {
+	if q.Artist < 0 {
+		return fmt.Errorf("%w: artist must be positive, got %d", InvalidQueryError, q.Artist)
+	}
+	if err := validateIDs("character", q.Characters); err != nil {
+		return err
+	}
+	if err := validateIDs("tag", q.Tags); err != nil {
+		return err
+	}
+	return q.Page.Validate()
+}
+
+// Encode makes Request out of ImageQuery
+func (q *ImageQuery) Encode() Request {
+	r := Request{}
+	for _, rating := range q.Rating {
+		r.Add("rating", string(rating))
+	}
+	setBool(r, "is_original", q.IsOriginal)
+	setBool(r, "is_screenshot", q.IsScreenshot)
+	setBool(r, "is_flagged", q.IsFlagged)
+	setBool(r, "is_animated", q.IsAnimated)
+	setInt(r, "artist", q.Artist)
+	addInts(r, "character", q.Characters)
+	addInts(r, "tag", q.Tags)
+	q.Page.encode(r)
+	return r
+}
+
+// TagQuery is a typed Request for Tags endpoint
+type TagQuery struct {
+	// Search looks for a tag by name or description
+	Search string
+	IsNSFW *bool
+	Page
+}
+
+// Validate checks that query values are in documented ranges
+func (q *TagQuery) Validate() error {
+	return q.Page.Validate()
+}
+
+// Encode makes Request out of TagQuery
+func (q *TagQuery) Encode() Request {
+	r := Request{}
+	setString(r, "search", q.Search)
+	setBool(r, "is_nsfw", q.IsNSFW)
+	q.Page.encode(r)
+	return r
+}
+
+// ArtistQuery is a typed Request for Artists endpoint
+type ArtistQuery struct {
+	// Search looks for an artist by name
+	Search       string
+	PolicyRepost *bool
+	PolicyCredit *bool
+	PolicyAI     *bool
+	Page
+}
+
+// Validate checks that query values are in documented ranges
+func (q *ArtistQuery) Validate() error {
+	return q.Page.Validate()
+}
+
+// Encode makes Request out of ArtistQuery
+func (q *ArtistQuery) Encode() Request {
+	r := Request{}
+	setString(r, "search", q.Search)
+	setBool(r, "policy_repost", q.PolicyRepost)
+	setBool(r, "policy_credit", q.PolicyCredit)
+	setBool(r, "policy_ai", q.PolicyAI)
+	q.Page.encode(r)
+	return r
+}
+
+// CharacterQuery is a typed Request for Characters endpoint
+type CharacterQuery struct {
+	// Search looks for a character by name or description
+	Search      string
+	Ages        []int
+	Gender      Gender
+	Species     string
+	Nationality string
+	Occupations []string
+	Page
+}
+
+// Validate checks that query values are in documented ranges
+func (q *CharacterQuery) Validate() error {
+	for _, age := range q.Ages {
+		if age < 0 {
+			return fmt.Errorf("%w: age must be >= 0, got %d", InvalidQueryError, age)
+		}
+	}
+	return q.Page.Validate()
+}
+
+// Encode makes Request out of CharacterQuery
+func (q *CharacterQuery) Encode() Request {
+	r := Request{}
+	setString(r, "search", q.Search)
+	addInts(r, "age", q.Ages)
+	setString(r, "gender", string(q.Gender))
+	setString(r, "species", q.Species)
+	setString(r, "nationality", q.Nationality)
+	for _, occupation := range q.Occupations {
+		r.Add("occupation", occupation)
+	}
+	q.Page.encode(r)
+	return r
+}
+
+func validateIDs(name string, ids []int) error {
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("%w: %s must be positive, got %d", InvalidQueryError, name, id)
+		}
+	}
+	return nil
+}
+
+func setString(r Request, key, value string) {
+	if value != "" {
+		r.Set(key, value)
+	}
+}
+
+func setInt(r Request, key string, value int) {
+	if value != 0 {
+		r.Set(key, strconv.Itoa(value))
+	}
+}
+
+func setBool(r Request, key string, value *bool) {
+	if value != nil {
+		r.Set(key, strconv.FormatBool(*value))
+	}
+}
+
+func addInts(r Request, key string, values []int) {
+	for _, v := range values {
+		r.Add(key, strconv.Itoa(v))
+	}
+}
@@ -0,0 +1,78 @@
+package necos
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestImageQuery(t *testing.T) {
+	q := ImageQuery{
+		Rating:     []Rating{"safe", "suggestive"},
+		IsAnimated: Bool(false),
+		Artist:     7,
+		Tags:       []int{1, 2},
+		Page:       Page{Limit: 10, Offset: 20},
+	}
+	require.NoError(t, q.Validate())
+
+	goal := Request{
+		"rating":      {"safe", "suggestive"},
+		"is_animated": {"false"},
+		"artist":      {"7"},
+		"tag":         {"1", "2"},
+		"limit":       {"10"},
+		"offset":      {"20"},
+	}
+	require.Equal(t, goal, q.Encode())
+
+	require.Equal(t, Request{}, (&ImageQuery{}).Encode())
+}
+
+func TestQueryEncode(t *testing.T) {
+	tags := TagQuery{Search: "cat", IsNSFW: Bool(false)}
+	require.Equal(t, Request{"search": {"cat"}, "is_nsfw": {"false"}}, tags.Encode())
+
+	artists := ArtistQuery{PolicyAI: Bool(true), Page: Page{Limit: 1}}
+	require.Equal(t, Request{"policy_ai": {"true"}, "limit": {"1"}}, artists.Encode())
+
+	characters := CharacterQuery{Ages: []int{16, 17}, Gender: "female", Occupations: []string{"student", "maid"}}
+	require.Equal(t, Request{
+		"age":        {"16", "17"},
+		"gender":     {"female"},
+		"occupation": {"student", "maid"},
+	}, characters.Encode())
+
+	require.Equal(t, Request{"offset": {"100"}}, Page{Offset: 100}.Encode())
+}
+
+func TestQueryValidate(t *testing.T) {
+	type validator interface {
+		Validate() error
+	}
+
+	tableTests := []struct {
+		name  string
+		query validator
+		valid bool
+	}{
+		{name: "empty", query: &ImageQuery{}, valid: true},
+		{name: "max_limit", query: &TagQuery{Page: Page{Limit: 100}}, valid: true},
+		{name: "big_limit", query: &TagQuery{Page: Page{Limit: 500}}},
+		{name: "negative_limit", query: &ArtistQuery{Page: Page{Limit: -1}}},
+		{name: "negative_offset", query: &CharacterQuery{Page: Page{Offset: -1}}},
+		{name: "negative_age", query: &CharacterQuery{Ages: []int{-3}}},
+		{name: "bad_tag", query: &ImageQuery{Tags: []int{0}}},
+		{name: "bad_artist", query: &ImageQuery{Artist: -5}},
+	}
+
+	for _, cs := range tableTests {
+		t.Run(cs.name, func(t *testing.T) {
+			err := cs.query.Validate()
+			if cs.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, InvalidQueryError)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package necos
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitPause is the pause made after 429 response without Retry-After
+const defaultRateLimitPause = time.Second
+
+// RateLimiter is a token bucket limiting the rate of requests made by Client
+//
+// it's safe for concurrent use, so one RateLimiter can be shared between several Clients
+type RateLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewRateLimiter creates RateLimiter allowing rps requests per second with bursts of up to burst requests
+//
+// rps <= 0 means no limit, but RateLimiter still pauses after server asks it to
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Wait blocks until the request is allowed to be made or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	delay := l.reserve(time.Now())
+	if err := sleep(ctx, delay); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+// reserve takes a token and returns the time to wait before using it
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var delay time.Duration
+	if l.rate > 0 {
+		if !l.last.IsZero() {
+			l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		}
+		l.last = now
+
+		l.tokens--
+		if l.tokens < 0 {
+			delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+
+	if pause := l.pausedUntil.Sub(now); pause > delay {
+		delay = pause
+	}
+	return delay
+}
+
+// cancel returns the token taken by canceled Wait
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate > 0 {
+		l.tokens = min(l.burst, l.tokens+1)
+	}
+}
+
+// PauseUntil makes all the requests wait until t
+func (l *RateLimiter) PauseUntil(t time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+	}
+}
+
+// Observe adapts the limiter to the response: it pauses after 429 (for Retry-After if it's given)
+// and when X-RateLimit-Remaining reaches 0 (until X-RateLimit-Reset)
+func (l *RateLimiter) Observe(response *http.Response) {
+	if l == nil {
+		return
+	}
+	now := time.Now()
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		pause := parseRetryAfter(response.Header.Get("Retry-After"), now)
+		if pause == 0 {
+			pause = defaultRateLimitPause
+		}
+		l.PauseUntil(now.Add(pause))
+	}
+
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, ok := parseRateLimitReset(response.Header.Get("X-RateLimit-Reset"), now); ok {
+			l.PauseUntil(reset)
+		}
+	}
+}
+
+// parseRateLimitReset parses X-RateLimit-Reset given either as unix time or as seconds left
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	reset, err := strconv.ParseFloat(value, 64)
+	if err != nil || reset < 0 {
+		return time.Time{}, false
+	}
+
+	// values that big can only be unix timestamps
+	if reset > 1e9 {
+		return time.Unix(0, int64(reset*float64(time.Second))), true
+	}
+	return now.Add(time.Duration(reset * float64(time.Second))), true
+}
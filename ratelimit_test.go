@@ -0,0 +1,114 @@
+package necos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+	now := time.Now()
+
+	// burst is available right away
+	require.Equal(t, time.Duration(0), l.reserve(now))
+	require.Equal(t, time.Duration(0), l.reserve(now))
+
+	// then tokens come every 100ms
+	require.Equal(t, 100*time.Millisecond, l.reserve(now))
+	require.Equal(t, 200*time.Millisecond, l.reserve(now))
+
+	// after a second bucket is full again
+	later := now.Add(time.Second)
+	require.Equal(t, time.Duration(0), l.reserve(later))
+	require.Equal(t, time.Duration(0), l.reserve(later))
+
+	l.PauseUntil(later.Add(time.Minute))
+	require.Equal(t, time.Minute, l.reserve(later))
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	t.Parallel()
+
+	l := NewRateLimiter(100, 1)
+
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, l.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	require.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+
+	l.PauseUntil(time.Now().Add(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.True(t, errors.Is(l.Wait(ctx), context.DeadlineExceeded))
+
+	var nilLimiter *RateLimiter
+	require.NoError(t, nilLimiter.Wait(context.Background()))
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+	now := time.Now()
+
+	l.Observe(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": {"2"}},
+	})
+	require.InDelta(t, 2*time.Second, l.reserve(now), float64(100*time.Millisecond))
+
+	reset := now.Add(time.Minute).Unix()
+	l.Observe(&http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(reset, 10)},
+		},
+	})
+	require.InDelta(t, time.Minute, l.reserve(now), float64(time.Second))
+}
+
+func TestClientRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`"ok"`))
+	}))
+	defer s.Close()
+
+	c := Client{
+		Domain:          s.URL,
+		Limiter:         NewRateLimiter(1, 1),
+		DownloadLimiter: NewRateLimiter(1000, 10),
+	}
+
+	var answer string
+	require.NoError(t, c.Get("/", nil, &answer))
+
+	// API budget is exhausted, but downloads have their own
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.DownloadAppend(context.Background(), s.URL, &bytes.Buffer{}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.True(t, errors.Is(c.GetWithContext(ctx, "/", nil, &answer), context.DeadlineExceeded))
+	require.Equal(t, int32(6), calls.Load())
+}
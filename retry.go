@@ -0,0 +1,129 @@
+package necos
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy describes how Client retries failed requests
+//
+// only idempotent requests (GET and HEAD) are retried, unless RetryNonIdempotent is set
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, values <= 1 disable retries
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, every next one is doubled
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential delay, values <= 0 cap it at an hour (Retry-After from the server isn't capped)
+	MaxDelay time.Duration
+	// Jitter is a fraction [0..1] of delay that is randomized
+	Jitter float64
+
+	// RetryableStatuses are status codes worth retrying
+	RetryableStatuses []int
+	// RetryableError decides whether a network error is worth retrying,
+	// if nil every error except context cancellation is retried
+	RetryableError func(err error) bool
+
+	// RetryNonIdempotent allows retrying POST requests (i.e. PostReport)
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns policy making up to 3 attempts on network errors, 429 and 5xx
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.2,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// shouldRetry decides whether request with given method failed with err on given attempt should be made again
+func (p *RetryPolicy) shouldRetry(method string, attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if method != http.MethodGet && method != http.MethodHead && !p.RetryNonIdempotent {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(p.RetryableStatuses, apiErr.StatusCode)
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return true
+}
+
+// maxBackoff caps the exponential delay when MaxDelay isn't set, so doubling doesn't overflow
+const maxBackoff = time.Hour
+
+// Backoff returns delay before the retry following given attempt (starting from 1)
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	limit := p.MaxDelay
+	if limit <= 0 {
+		limit = maxBackoff
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay > 0 && delay < limit; i++ {
+		if delay > limit/2 {
+			delay = limit
+		} else {
+			delay *= 2
+		}
+	}
+	delay = min(delay, limit)
+
+	if p.Jitter > 0 && delay > 0 {
+		spread := time.Duration(float64(delay) * min(p.Jitter, 1))
+		delay = delay - spread + rand.N(2*spread+1)
+	}
+	return delay
+}
+
+// wait sleeps before the next attempt, honoring Retry-After of err if there's one
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	delay := p.Backoff(attempt)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		delay = apiErr.RetryAfter
+	}
+
+	return sleep(ctx, delay)
+}
+
+// sleep waits for given duration or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
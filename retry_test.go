@@ -0,0 +1,155 @@
+package necos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer responds with given status fails times and then answers with "ok"
+func failingServer(status int, fails int32, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= fails {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`"ok"`))
+	}))
+}
+
+func fastRetry() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("recovers", func(t *testing.T) {
+		var calls atomic.Int32
+		s := failingServer(http.StatusInternalServerError, 2, &calls)
+		defer s.Close()
+
+		c := Client{Domain: s.URL, Retry: fastRetry()}
+
+		var answer string
+		require.NoError(t, c.Get("/", nil, &answer))
+		require.Equal(t, "ok", answer)
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives_up", func(t *testing.T) {
+		var calls atomic.Int32
+		s := failingServer(http.StatusBadGateway, 10, &calls)
+		defer s.Close()
+
+		c := Client{Domain: s.URL, Retry: fastRetry()}
+
+		err := c.Get("/", nil, nil)
+		require.True(t, IsServerError(err))
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("not_retryable_status", func(t *testing.T) {
+		var calls atomic.Int32
+		s := failingServer(http.StatusNotFound, 10, &calls)
+		defer s.Close()
+
+		c := Client{Domain: s.URL, Retry: fastRetry()}
+
+		require.True(t, IsNotFound(c.Get("/", nil, nil)))
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("no_policy", func(t *testing.T) {
+		var calls atomic.Int32
+		s := failingServer(http.StatusInternalServerError, 10, &calls)
+		defer s.Close()
+
+		c := Client{Domain: s.URL}
+
+		require.Error(t, c.Get("/", nil, nil))
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("post_not_retried", func(t *testing.T) {
+		var calls atomic.Int32
+		s := failingServer(http.StatusServiceUnavailable, 10, &calls)
+		defer s.Close()
+
+		c := Client{Domain: s.URL, Retry: fastRetry()}
+
+		require.Error(t, c.PostReport(Report{"id": {"1"}}))
+		require.Equal(t, int32(1), calls.Load())
+
+		c.Retry.RetryNonIdempotent = true
+		require.Error(t, c.PostReport(Report{"id": {"1"}}))
+		require.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("download", func(t *testing.T) {
+		var calls atomic.Int32
+		s := failingServer(http.StatusTooManyRequests, 1, &calls)
+		defer s.Close()
+
+		c := Client{Retry: fastRetry()}
+
+		var buf bytes.Buffer
+		require.NoError(t, c.DownloadAppend(context.Background(), s.URL, &buf))
+		require.Equal(t, `"ok"`, buf.String())
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("context", func(t *testing.T) {
+		var calls atomic.Int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer s.Close()
+
+		c := Client{Domain: s.URL, Retry: fastRetry()}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := c.GetWithContext(ctx, "/", nil, nil)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	require.Equal(t, 100*time.Millisecond, p.Backoff(1))
+	require.Equal(t, 200*time.Millisecond, p.Backoff(2))
+	require.Equal(t, 400*time.Millisecond, p.Backoff(3))
+	require.Equal(t, time.Second, p.Backoff(10))
+
+	// without MaxDelay doubling stops at maxBackoff instead of overflowing
+	p.MaxDelay = 0
+	require.Equal(t, 800*time.Millisecond, p.Backoff(4))
+	require.Equal(t, maxBackoff, p.Backoff(100))
+	require.Equal(t, maxBackoff, p.Backoff(math.MaxInt))
+	p.MaxDelay = math.MaxInt64
+	require.Equal(t, time.Duration(math.MaxInt64), p.Backoff(100))
+	p.MaxDelay = time.Second
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.Backoff(1)
+		require.GreaterOrEqual(t, d, 50*time.Millisecond)
+		require.LessOrEqual(t, d, 150*time.Millisecond)
+	}
+}
@@ -0,0 +1,78 @@
+package necos
+
+import (
+	"context"
+	"io"
+)
+
+// ImageService groups Client methods working with images,
+// depend on it (or on other services) instead of *Client to be able to use fakes in tests
+type ImageService interface {
+	GetImages(req Request) (MultipleContainer[Image], error)
+	GetImagesWithContext(ctx context.Context, req Request) (MultipleContainer[Image], error)
+	GetRandomImages(req Request) (MultipleContainer[Image], error)
+	GetRandomImagesWithContext(ctx context.Context, req Request) (MultipleContainer[Image], error)
+	GetRandomImageFileURL(req Request) (string, error)
+	GetRandomImageFileURLWithContext(ctx context.Context, req Request) (string, error)
+	GetRandomImageFile(req Request, dst io.Writer) error
+	GetRandomImageFileWithContext(ctx context.Context, req Request, dst io.Writer) error
+	GetImageByID(id int) (Image, error)
+	GetImageByIDWithContext(ctx context.Context, id int) (Image, error)
+	GetImageArtist(id int) (Artist, error)
+	GetImageArtistWithContext(ctx context.Context, id int) (Artist, error)
+	GetImageCharacters(id int, req Request) (MultipleContainer[Character], error)
+	GetImageCharactersWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Character], error)
+	GetImageTags(id int, req Request) (MultipleContainer[Tag], error)
+	GetImageTagsWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Tag], error)
+	PostReport(req Report) error
+	PostReportWithContext(ctx context.Context, req Report) error
+}
+
+// TagService groups Client methods working with tags
+type TagService interface {
+	GetTags(req Request) (MultipleContainer[Tag], error)
+	GetTagsWithContext(ctx context.Context, req Request) (MultipleContainer[Tag], error)
+	GetTagByID(id int) (Tag, error)
+	GetTagByIDWithContext(ctx context.Context, id int) (Tag, error)
+	GetTagImages(tagID int, req Request) (MultipleContainer[Image], error)
+	GetTagImagesWithContext(ctx context.Context, tagID int, req Request) (MultipleContainer[Image], error)
+}
+
+// ArtistService groups Client methods working with artists
+type ArtistService interface {
+	GetArtists(req Request) (MultipleContainer[Artist], error)
+	GetArtistsWithContext(ctx context.Context, req Request) (MultipleContainer[Artist], error)
+	GetArtistByID(id int) (Artist, error)
+	GetArtistByIDWithContext(ctx context.Context, id int) (Artist, error)
+	GetArtistImages(id int, req Request) (MultipleContainer[Image], error)
+	GetArtistImagesWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Image], error)
+}
+
+// CharacterService groups Client methods working with characters
+type CharacterService interface {
+	GetCharacters(req Request) (MultipleContainer[Character], error)
+	GetCharactersWithContext(ctx context.Context, req Request) (MultipleContainer[Character], error)
+	GetCharacterByID(id int) (Character, error)
+	GetCharacterByIDWithContext(ctx context.Context, id int) (Character, error)
+	GetCharacterImages(id int, req Request) (MultipleContainer[Image], error)
+	GetCharacterImagesWithContext(ctx context.Context, id int, req Request) (MultipleContainer[Image], error)
+}
+
+// Downloader groups Client methods downloading image files
+type Downloader interface {
+	DownloadAppend(ctx context.Context, url string, dst io.Writer) error
+	Download(ctx context.Context, url string, dst io.WriteCloser) error
+	DownloadImage(im *Image, dst io.WriteCloser) error
+	DownloadImageWithContext(ctx context.Context, im *Image, dst io.WriteCloser) error
+	DownloadSample(im *Image, dst io.WriteCloser) error
+	DownloadSampleWithContext(ctx context.Context, im *Image, dst io.WriteCloser) error
+}
+
+// Client satisfies all the services
+var (
+	_ ImageService     = (*Client)(nil)
+	_ TagService       = (*Client)(nil)
+	_ ArtistService    = (*Client)(nil)
+	_ CharacterService = (*Client)(nil)
+	_ Downloader       = (*Client)(nil)
+)
@@ -0,0 +1,161 @@
+package necos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Stream makes GET call to list endpoint with given path and decodes items of the response one by one,
+// calling fn for each of them as soon as it's read, so the whole page is never kept in memory
+//
+// returning false from fn stops the decoding and closes the response (it's not an error).
+// Streaming calls don't use Client.Cache and fail with NotCachedError when Client is Offline
+func Stream[T any](ctx context.Context, c *Client, path string, query url.Values, fn func(T) bool) error {
+	r := &request{method: http.MethodGet, url: c.buildURL(path, query), path: path, query: query, endpoint: endpointOf(ctx, "", templateOf(path))}
+
+	ctx, start := c.begin(ctx, r)
+	n, err := stream(ctx, c, r, fn)
+	c.finish(ctx, r, start, int(n), nil, err)
+	return err
+}
+
+// StreamChan is Stream delivering items to channel
+//
+// items channel is closed when the response ends, after that the error (or nil) is sent to error channel.
+// The caller must either read all the items or cancel ctx, which stops the streaming
+func StreamChan[T any](ctx context.Context, c *Client, path string, query url.Values) (<-chan T, <-chan error) {
+	items := make(chan T)
+	errc := make(chan error, 1)
+
+	go func() {
+		err := Stream(ctx, c, path, query, func(item T) bool {
+			select {
+			case items <- item:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if err == nil {
+			err = ctx.Err()
+		}
+
+		close(items)
+		errc <- err
+		close(errc)
+	}()
+	return items, errc
+}
+
+// StreamImages streams images of Images endpoint to fn, see Stream
+//
+// For more info on Request parameters see GetImages
+func (c *Client) StreamImages(ctx context.Context, req Request, fn func(Image) bool) error {
+	return Stream(endpointContext(ctx, "StreamImages", Images), c, Images, req, fn)
+}
+
+// StreamImagesChan streams images of Images endpoint to channel, see StreamChan
+//
+// For more info on Request parameters see GetImages
+func (c *Client) StreamImagesChan(ctx context.Context, req Request) (<-chan Image, <-chan error) {
+	return StreamChan[Image](endpointContext(ctx, "StreamImages", Images), c, Images, req)
+}
+
+// stream makes the request and decodes its items, returning the amount of bytes read
+func stream[T any](ctx context.Context, c *Client, r *request, fn func(T) bool) (int64, error) {
+	if c.Offline {
+		return 0, fmt.Errorf("%w: %s %s", NotCachedError, r.method, r.url)
+	}
+
+	response, err := c.send(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	counter := &countingReader{r: response.Body}
+	err = decodeItems(ctx, json.NewDecoder(counter), func(raw json.RawMessage) (bool, error) {
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return false, err
+		}
+		if err := c.checkDrift(r, raw, &item); err != nil {
+			return false, err
+		}
+		return fn(item), nil
+	})
+	return counter.n, err
+}
+
+// decodeItems reads "items" array of JSON object element by element, skipping other fields
+//
+// fn is called with every element, it can stop the decoding by returning false
+func decodeItems(ctx context.Context, dec *json.Decoder, fn func(json.RawMessage) (bool, error)) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if key, _ := token.(string); !strings.EqualFold(key, "items") {
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			var item json.RawMessage
+			if err = dec.Decode(&item); err != nil {
+				return err
+			}
+			if ok, err := fn(item); !ok {
+				return err
+			}
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("necos: unexpected %v in response, expected %v", token, delim)
+	}
+	return nil
+}
+
+// countingReader counts bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
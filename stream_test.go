@@ -0,0 +1,129 @@
+package necos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// streamServer responds with a page of n images, writing them one by one
+func streamServer(t *testing.T, n int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	written := new(atomic.Int64)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"extra": {"nested": [1, 2]}, "items": [`)
+		for i := 1; i <= n; i++ {
+			if i > 1 {
+				_, _ = fmt.Fprint(w, ",")
+			}
+			data, _ := json.Marshal(Image{ID: i, Tags: []Tag{{ID: i, Name: strings.Repeat("t", 10000)}}})
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			written.Store(int64(i))
+			w.(http.Flusher).Flush()
+		}
+		_, _ = fmt.Fprintf(w, `], "count": %d}`, n)
+	}))
+	t.Cleanup(s.Close)
+	return s, written
+}
+
+func TestStreamImages(t *testing.T) {
+	s, _ := streamServer(t, 5)
+	c := NewClient(WithDomain(s.URL))
+
+	var ids []int
+	require.NoError(t, c.StreamImages(context.Background(), nil, func(im Image) bool {
+		ids = append(ids, im.ID)
+		return true
+	}))
+	require.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+
+	// early stop
+	ids = nil
+	require.NoError(t, c.StreamImages(context.Background(), nil, func(im Image) bool {
+		ids = append(ids, im.ID)
+		return im.ID < 2
+	}))
+	require.Equal(t, []int{1, 2}, ids)
+
+	// generic version works with other types
+	var tags []Tag
+	require.NoError(t, Stream(context.Background(), c, Tags, nil, func(tag Tag) bool {
+		tags = append(tags, tag)
+		return true
+	}))
+	require.Len(t, tags, 5)
+}
+
+func TestStreamStopsReading(t *testing.T) {
+	s, written := streamServer(t, 1000)
+	c := NewClient(WithDomain(s.URL))
+
+	require.NoError(t, c.StreamImages(context.Background(), nil, func(im Image) bool {
+		return false
+	}))
+	require.Less(t, written.Load(), int64(1000))
+}
+
+func TestStreamErrors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case Images:
+			_, _ = fmt.Fprint(w, `{"items": [{"id": 1}, {"id": "two"}]}`)
+		case Tags:
+			_, _ = fmt.Fprint(w, `["not", "an", "object"]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	c := NewClient(WithDomain(s.URL))
+
+	var ids []int
+	err := c.StreamImages(context.Background(), nil, func(im Image) bool {
+		ids = append(ids, im.ID)
+		return true
+	})
+	require.Error(t, err)
+	require.Equal(t, []int{1}, ids)
+
+	require.Error(t, Stream(context.Background(), c, Tags, nil, func(Tag) bool { return true }))
+	require.True(t, IsNotFound(Stream(context.Background(), c, Artists, nil, func(Artist) bool { return true })))
+
+	c.Offline = true
+	require.ErrorIs(t, Stream(context.Background(), c, Tags, nil, func(Tag) bool { return true }), NotCachedError)
+}
+
+func TestStreamImagesChan(t *testing.T) {
+	s, _ := streamServer(t, 3)
+	c := NewClient(WithDomain(s.URL))
+
+	images, errc := c.StreamImagesChan(context.Background(), nil)
+	var ids []int
+	for im := range images {
+		ids = append(ids, im.ID)
+	}
+	require.NoError(t, <-errc)
+	require.Equal(t, []int{1, 2, 3}, ids)
+
+	// cancellation stops the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	images, errc = c.StreamImagesChan(ctx, nil)
+	im := <-images
+	require.Equal(t, 1, im.ID)
+	cancel()
+
+	for range images {
+	}
+	require.ErrorIs(t, <-errc, context.Canceled)
+}
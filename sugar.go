@@ -2,17 +2,22 @@ package necos
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 )
 
 var (
-	safeRequest = Request{"rating": []string{"safe"}}
+	safeRequest = Request{"rating": []string{RatingSafe.String()}}
 	oneValue    = Request{"limit": {"1"}}
 )
 
@@ -26,7 +31,8 @@ func OneValue() Request {
 
 // SetFields sets given fields to Request;
 // odd are considered keys and even values
-// args should be convertible to string
+// args should be convertible to string,
+// slice values (like []Rating) set all of their elements
 //
 // if given nil Request makes Request itself
 //
@@ -40,14 +46,15 @@ func SetFields(r Request, args ...any) Request {
 	}
 
 	for i := 0; i < len(args); i += 2 {
-		r.Set(fmt.Sprint(args[i]), fmt.Sprint(args[i+1]))
+		r[fmt.Sprint(args[i])] = fieldValues(args[i+1])
 	}
 	return r
 }
 
 // AddFields adds given fields to Request,
 // odd are considered keys and even values,
-// args should be convertible to string
+// args should be convertible to string,
+// slice values (like []Rating) add all of their elements
 //
 // if given nil Request makes Request itself
 //
@@ -61,11 +68,26 @@ func AddFields(r Request, args ...any) Request {
 	}
 
 	for i := 0; i < len(args); i += 2 {
-		r.Add(fmt.Sprint(args[i]), fmt.Sprint(args[i+1]))
+		key := fmt.Sprint(args[i])
+		r[key] = append(r[key], fieldValues(args[i+1])...)
 	}
 	return r
 }
 
+// fieldValues converts value to strings, slices are converted element by element
+func fieldValues(value any) []string {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return []string{fmt.Sprint(value)}
+	}
+
+	values := make([]string, v.Len())
+	for i := range values {
+		values[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return values
+}
+
 // GetName returns Image name
 func (im *Image) GetName() string {
 	return filepath.Base(im.ImageURL)
@@ -145,30 +167,87 @@ func SaveToSlice(dst *[]byte) io.WriteCloser {
 
 // DownloadAppend is the method used to do append downloaded to given writer
 //
-// it makes a GET request to given url and writes received content to dst
+// it makes a GET request to given url and writes received content to dst,
+// the request is retried according to Retry policy only until the content starts being written
 func (c *Client) DownloadAppend(ctx context.Context, url string, dst io.Writer) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
-	if err != nil {
-		return err
+	return c.downloadAppend(ctx, url, "", dst)
+}
+
+// downloadAppend downloads content of url to dst, using DownloadCache (see download)
+func (c *Client) downloadAppend(ctx context.Context, url, hashMD5 string, dst io.Writer) error {
+	r := &request{method: http.MethodGet, url: url, path: url, download: true, endpoint: endpointOf(ctx, "DownloadAppend", "")}
+
+	ctx, start := c.begin(ctx, r)
+	n, err := c.download(ctx, r, hashMD5, dst)
+	c.finish(ctx, r, start, int(n), nil, err)
+	return err
+}
+
+// download makes the download request r, returning the amount of bytes written to dst
+//
+// content is cached by hashMD5 when it's known and matches md5 of the downloaded bytes,
+// so any url of the image finds it, otherwise it's cached by url
+func (c *Client) download(ctx context.Context, r *request, hashMD5 string, dst io.Writer) (int64, error) {
+	urlKey := cacheKey(http.MethodGet, r.url)
+	if c.DownloadCache != nil {
+		r.cache = cacheMiss
+		keys := []string{urlKey}
+		if hashMD5 != "" {
+			keys = []string{imageCacheKey(hashMD5), urlKey}
+		}
+		for _, key := range keys {
+			if entry, ok := c.DownloadCache.Get(key); ok {
+				r.cache = cacheHit
+				n, err := dst.Write(entry.Body)
+				return int64(n), err
+			}
+		}
+	}
+	if c.Offline {
+		return 0, fmt.Errorf("%w: %s", NotCachedError, r.url)
 	}
 
-	response, err := c.Do(req)
+	response, err := c.send(ctx, r)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer response.Body.Close()
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: %s", BadStatusError, response.Status)
+
+	if c.DownloadCache == nil {
+		return io.Copy(dst, response.Body)
 	}
 
-	_, err = io.Copy(dst, response.Body)
-	return err
+	var body bytes.Buffer
+	hash := md5.New()
+	n, err := io.Copy(io.MultiWriter(dst, &body, hash), response.Body)
+	if err != nil {
+		return n, err
+	}
+
+	// content which doesn't match the hash (e.g. replaced by proxy) must not be served for other urls
+	key := urlKey
+	if hashMD5 != "" && strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), hashMD5) {
+		key = imageCacheKey(hashMD5)
+	}
+	c.DownloadCache.Set(key, &CacheEntry{Body: body.Bytes()})
+	return n, nil
+}
+
+// downloadImage downloads url into dst, caching content by hashMD5 when it's known
+func (c *Client) downloadImage(ctx context.Context, url, hashMD5 string, dst io.WriteCloser) error {
+	if err := c.downloadAppend(ctx, url, hashMD5, dst); err != nil {
+		return err
+	}
+	return dst.Close()
 }
 
 // Download is the method used to download Images
 //
 // Closes the file after finished reading
 func (c *Client) Download(ctx context.Context, url string, dst io.WriteCloser) error {
+	if _, ok := EndpointFromContext(ctx); !ok {
+		ctx = endpointContext(ctx, "Download", "")
+	}
 	if err := c.DownloadAppend(ctx, url, dst); err != nil {
 		return err
 	}
@@ -186,7 +265,7 @@ func (c *Client) DownloadImage(im *Image, dst io.WriteCloser) error {
 //
 // closes the Writer
 func (c *Client) DownloadImageWithContext(ctx context.Context, im *Image, dst io.WriteCloser) error {
-	return c.Download(ctx, im.ImageURL, dst)
+	return c.downloadImage(endpointContext(ctx, "DownloadImage", ""), im.ImageURL, im.HashMD5, dst)
 }
 
 // DownloadSample downloads the sample of Image with default context
@@ -200,5 +279,5 @@ func (c *Client) DownloadSample(im *Image, dst io.WriteCloser) error {
 //
 // closes the Writer
 func (c *Client) DownloadSampleWithContext(ctx context.Context, im *Image, dst io.WriteCloser) error {
-	return c.Download(ctx, im.SampleURL, dst)
+	return c.Download(endpointContext(ctx, "DownloadSample", ""), im.SampleURL, dst)
 }
@@ -0,0 +1,147 @@
+package necos
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp is a moment API gives as fractional Unix seconds (e.g. 1711315478.870374)
+//
+// it's decoded with nanosecond precision without going through float64, so it's marshalled back as it was
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp creates Timestamp of t
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// ParseTimestamp parses decimal Unix seconds
+func ParseTimestamp(s string) (Timestamp, error) {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	negative := strings.HasPrefix(whole, "-")
+
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || (hasFrac && (frac == "" || strings.ContainsAny(frac, "+-"))) {
+		return parseFloatTimestamp(s)
+	}
+
+	var nsec int64
+	if hasFrac {
+		// digits beyond nanoseconds are dropped
+		digits := (frac + "000000000")[:9]
+		if nsec, err = strconv.ParseInt(digits, 10, 64); err != nil {
+			return parseFloatTimestamp(s)
+		}
+	}
+	if negative {
+		nsec = -nsec
+	}
+	return Timestamp{Time: time.Unix(sec, nsec)}, nil
+}
+
+// parseFloatTimestamp parses timestamps in other notations (like 1.7e9) losing precision
+func parseFloatTimestamp(s string) (Timestamp, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return Timestamp{}, fmt.Errorf("necos: invalid timestamp %q", s)
+	}
+
+	sec, frac := math.Modf(f)
+	return Timestamp{Time: time.Unix(int64(sec), int64(math.Round(frac*1e9)))}, nil
+}
+
+// String formats Timestamp as decimal Unix seconds without trailing zeros
+func (t Timestamp) String() string {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+
+	sign := ""
+	if sec < 0 && nsec > 0 {
+		// time.Unix rounds seconds down, so -1.5 is -2 seconds and 500000000 nanoseconds
+		sign = "-"
+		sec, nsec = -(sec + 1), 1e9-nsec
+	} else if sec < 0 {
+		sign, sec = "-", -sec
+	}
+
+	s := sign + strconv.FormatInt(sec, 10)
+	if nsec == 0 {
+		return s
+	}
+	return s + "." + strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Timestamp{}
+		return nil
+	}
+
+	// some APIs quote numbers
+	parsed, err := ParseTimestamp(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// CreatedAfter returns predicate keeping images created after t
+func CreatedAfter(t time.Time) func(Image) bool {
+	return func(im Image) bool {
+		return im.CreatedAt.After(t)
+	}
+}
+
+// CreatedBefore returns predicate keeping images created before t
+func CreatedBefore(t time.Time) func(Image) bool {
+	return func(im Image) bool {
+		return im.CreatedAt.Before(t)
+	}
+}
+
+// UpdatedAfter returns predicate keeping images updated after t
+func UpdatedAfter(t time.Time) func(Image) bool {
+	return func(im Image) bool {
+		return im.UpdatedAt.After(t)
+	}
+}
+
+// Filter returns iterator yielding only items of seq which keep returns true for, errors are yielded as is
+//
+// it's meant to be used with iterators like AllImages, e.g. Filter(c.AllImages(ctx, nil), CreatedAfter(t))
+func Filter[T any](seq iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range seq {
+			if err == nil && !keep(item) {
+				continue
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// ByCreatedAt compares images by CreatedAt, it can be used with slices.SortFunc
+func ByCreatedAt(a, b Image) int {
+	return a.CreatedAt.Compare(b.CreatedAt.Time)
+}
+
+// ByUpdatedAt compares images by UpdatedAt, it can be used with slices.SortFunc
+func ByUpdatedAt(a, b Image) int {
+	return a.UpdatedAt.Compare(b.UpdatedAt.Time)
+}
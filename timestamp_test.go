@@ -0,0 +1,121 @@
+package necos
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTimestampRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"1711315478.870374",
+		"1711315478.000001",
+		"1711315478.123456789",
+		"1711315478",
+		"0",
+		"-1.5",
+		"-0.25",
+		"-3",
+	} {
+		ts, err := ParseTimestamp(s)
+		require.NoError(t, err, s)
+		require.Equal(t, s, ts.String())
+
+		var decoded Timestamp
+		require.NoError(t, json.Unmarshal([]byte(s), &decoded))
+		require.True(t, ts.Equal(decoded.Time))
+
+		data, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		require.Equal(t, s, string(data))
+	}
+
+	ts, err := ParseTimestamp("1711315478.870374")
+	require.NoError(t, err)
+	require.Equal(t, int64(1711315478), ts.Unix())
+	require.Equal(t, 870374000, ts.Nanosecond())
+
+	// the value doesn't go through float64, which would lose microseconds
+	f, err := strconv.ParseFloat("1711315478.870374", 64)
+	require.NoError(t, err)
+	require.NotEqual(t, ts.UnixNano(), int64(f*1e9))
+}
+
+func TestTimestampParsing(t *testing.T) {
+	ts, err := ParseTimestamp("1.7e9")
+	require.NoError(t, err)
+	require.Equal(t, int64(1.7e9), ts.Unix())
+
+	ts, err = ParseTimestamp("1.1234567899")
+	require.NoError(t, err)
+	require.Equal(t, "1.123456789", ts.String())
+
+	var quoted Timestamp
+	require.NoError(t, json.Unmarshal([]byte(`"12.5"`), &quoted))
+	require.Equal(t, "12.5", quoted.String())
+
+	for _, s := range []string{"", "abc", "1.-5", "1.2.3", "NaN", "Inf"} {
+		_, err = ParseTimestamp(s)
+		require.Error(t, err, s)
+	}
+
+	var im Image
+	require.NoError(t, json.Unmarshal([]byte(`{"created_at": null, "updated_at": 10.25}`), &im))
+	require.True(t, im.CreatedAt.IsZero())
+	require.Equal(t, time.Unix(10, 250000000), im.UpdatedAt.Time)
+
+	data, err := json.Marshal(Timestamp{})
+	require.NoError(t, err)
+	require.Equal(t, "null", string(data))
+}
+
+func TestTimestampHelpers(t *testing.T) {
+	base := time.Unix(1.7e9, 0)
+	images := []Image{
+		{ID: 1, CreatedAt: NewTimestamp(base.Add(2 * time.Hour)), UpdatedAt: NewTimestamp(base.Add(5 * time.Hour))},
+		{ID: 2, CreatedAt: NewTimestamp(base), UpdatedAt: NewTimestamp(base.Add(3 * time.Hour))},
+		{ID: 3, CreatedAt: NewTimestamp(base.Add(time.Hour)), UpdatedAt: NewTimestamp(base.Add(4 * time.Hour))},
+	}
+
+	sorted := slices.Clone(images)
+	slices.SortFunc(sorted, ByCreatedAt)
+	require.Equal(t, []int{2, 3, 1}, imageIDs(sorted))
+	slices.SortFunc(sorted, ByUpdatedAt)
+	require.Equal(t, []int{2, 3, 1}, imageIDs(sorted))
+
+	seq := func(yield func(Image, error) bool) {
+		for _, im := range images {
+			if !yield(im, nil) {
+				return
+			}
+		}
+		yield(Image{}, BadStatusError)
+	}
+
+	var ids []int
+	var errs []error
+	for im, err := range Filter(iter.Seq2[Image, error](seq), CreatedAfter(base.Add(30*time.Minute))) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, im.ID)
+	}
+	require.Equal(t, []int{1, 3}, ids)
+	require.Equal(t, []error{BadStatusError}, errs)
+
+	require.True(t, CreatedBefore(base.Add(time.Minute))(images[1]))
+	require.False(t, UpdatedAfter(base.Add(4*time.Hour))(images[2]))
+}
+
+func imageIDs(images []Image) []int {
+	ids := make([]int, len(images))
+	for i, im := range images {
+		ids[i] = im.ID
+	}
+	return ids
+}
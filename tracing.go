@@ -0,0 +1,248 @@
+package necos
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans of API calls and image downloads made by Client
+//
+// it's a small abstraction, which can be implemented over any telemetry SDK (e.g. OpenTelemetry)
+type Tracer interface {
+	// Start starts span with given name as a child of span in ctx and returns context carrying it
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation
+type Span interface {
+	// SetAttributes sets attributes of span, the later values override the earlier ones
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks span as failed with err
+	RecordError(err error)
+	// End finishes span
+	End()
+	// SpanContext returns identifiers of span, which are propagated with outgoing requests
+	SpanContext() SpanContext
+}
+
+// Attribute is a key-value pair describing Span
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr creates Attribute
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanContext identifies span in a trace
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether both TraceID and SpanID are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent formats SpanContext as W3C traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// NoopTracer is Tracer that doesn't trace anything, it's used when Client.Tracer is nil
+type NoopTracer struct{}
+
+// Start returns ctx and span which does nothing
+func (NoopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+func (noopSpan) SpanContext() SpanContext   { return SpanContext{} }
+
+// tracer returns Client.Tracer or NoopTracer if it isn't set
+func (c *Client) tracer() Tracer {
+	if c.Tracer == nil {
+		return NoopTracer{}
+	}
+	return c.Tracer
+}
+
+// spanName returns name of span tracing the request
+func spanName(r *request) string {
+	if r.endpoint.Name != "" {
+		return "necos." + r.endpoint.Name
+	}
+	return "necos." + r.method + " " + r.endpoint.Template
+}
+
+// endSpan sets attributes describing the finished request and ends its span
+func (c *Client) endSpan(r *request, bytes int, err error) {
+	attrs := []Attribute{
+		Attr("necos.endpoint", r.endpoint.Name),
+		Attr("http.request.method", r.method),
+		Attr("necos.response.size", bytes),
+		Attr("necos.attempts", r.attempts),
+	}
+	if r.download {
+		attrs = append(attrs, Attr("necos.download", true))
+	} else {
+		attrs = append(attrs, Attr("necos.template", r.endpoint.Template))
+	}
+	if len(r.endpoint.Params) == 1 {
+		attrs = append(attrs, Attr("necos.id", r.endpoint.Params[0]))
+	} else if len(r.endpoint.Params) > 1 {
+		attrs = append(attrs, Attr("necos.params", r.endpoint.Params))
+	}
+	if r.status != 0 {
+		attrs = append(attrs, Attr("http.response.status_code", r.status))
+	}
+	if r.cache != "" {
+		attrs = append(attrs, Attr("necos.cache", r.cache))
+	}
+
+	r.span.SetAttributes(attrs...)
+	if err != nil {
+		r.span.RecordError(err)
+	}
+	r.span.End()
+}
+
+// SpanRecorder is Tracer keeping spans in memory, it's meant to be used in tests
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+var _ Tracer = (*SpanRecorder)(nil)
+
+// RecordedSpan is a span finished by SpanRecorder
+type RecordedSpan struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext
+	Attributes map[string]any
+	Err        error
+	Start, End time.Time
+}
+
+// NewSpanRecorder creates empty SpanRecorder
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+type spanKey struct{}
+
+// Start starts span as a child of SpanRecorder span in ctx, or as a root of new trace if there's none
+func (r *SpanRecorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{
+		recorder: r,
+		span: RecordedSpan{
+			Name:       name,
+			Attributes: make(map[string]any),
+			Start:      time.Now(),
+		},
+	}
+
+	if parent, ok := ctx.Value(spanKey{}).(*recordingSpan); ok {
+		span.span.Parent = parent.span.Context
+		span.span.Context.TraceID = parent.span.Context.TraceID
+	} else {
+		fillRandom(span.span.Context.TraceID[:])
+	}
+	fillRandom(span.span.Context.SpanID[:])
+	span.span.Context.Sampled = true
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Spans returns finished spans in order of their ending
+func (r *SpanRecorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.spans)
+}
+
+// Reset forgets finished spans
+func (r *SpanRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+}
+
+type recordingSpan struct {
+	recorder *SpanRecorder
+
+	mu    sync.Mutex
+	span  RecordedSpan
+	ended bool
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, attr := range attrs {
+		s.span.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.span.Err = err
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.span.End = time.Now()
+	span := s.span
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.recorder.spans = append(s.recorder.spans, span)
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	return s.span.Context
+}
+
+func fillRandom(b []byte) {
+	for i := range b {
+		b[i] = byte(rand.Uint32())
+	}
+}
+
+// injectTraceParent adds traceparent header of span to req
+func injectTraceParent(req *http.Request, span Span) {
+	if sc := span.SpanContext(); sc.IsValid() {
+		req.Header.Set("traceparent", sc.TraceParent())
+	}
+}
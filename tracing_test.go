@@ -0,0 +1,99 @@
+package necos
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSpanContextTraceParent(t *testing.T) {
+	sc := SpanContext{Sampled: true}
+	require.False(t, sc.IsValid())
+
+	sc.TraceID[0], sc.TraceID[15] = 0x4b, 0xf9
+	sc.SpanID[7] = 0x01
+	require.True(t, sc.IsValid())
+	require.Equal(t, "00-4b0000000000000000000000000000f9-0000000000000001-01", sc.TraceParent())
+}
+
+func TestTracing(t *testing.T) {
+	var calls int
+	var traceparents []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		switch {
+		case r.URL.Path == "/files/1.png":
+			_, _ = w.Write([]byte("image"))
+		case r.URL.Path == "/artists/3" && calls == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/artists/3":
+			_, _ = w.Write([]byte(`{"id": 3}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	// nothing is propagated by default
+	_, err := NewClient(WithDomain(s.URL)).GetTagByID(1)
+	require.True(t, IsNotFound(err))
+	require.Equal(t, []string{""}, traceparents)
+	calls, traceparents = 0, nil
+
+	recorder := NewSpanRecorder()
+	c := NewClient(
+		WithDomain(s.URL),
+		WithTracer(recorder),
+		WithRetry(&RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryableStatuses: []int{http.StatusBadGateway}}),
+	)
+
+	ctx, parent := recorder.Start(context.Background(), "test")
+	artist, err := c.GetArtistByIDWithContext(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, artist.ID)
+
+	var content []byte
+	require.NoError(t, c.DownloadImage(&Image{ImageURL: s.URL + "/files/1.png"}, SaveToSlice(&content)))
+	_, err = c.GetTagByID(1)
+	require.Error(t, err)
+	parent.End()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 4)
+
+	call := spans[0]
+	require.Equal(t, "necos.GetArtistByID", call.Name)
+	require.Equal(t, parent.SpanContext(), call.Parent)
+	require.Equal(t, parent.SpanContext().TraceID, call.Context.TraceID)
+	require.NoError(t, call.Err)
+	require.Equal(t, map[string]any{
+		"necos.endpoint":            "GetArtistByID",
+		"necos.template":            ArtistByID,
+		"necos.id":                  3,
+		"http.request.method":       http.MethodGet,
+		"http.response.status_code": http.StatusOK,
+		"necos.response.size":       len(`{"id": 3}`),
+		"necos.attempts":            2,
+	}, call.Attributes)
+
+	// both attempts carry the span context
+	require.Equal(t, []string{call.Context.TraceParent(), call.Context.TraceParent()}, traceparents[:2])
+
+	download := spans[1]
+	require.Equal(t, "necos.DownloadImage", download.Name)
+	require.Equal(t, true, download.Attributes["necos.download"])
+	require.Equal(t, len("image"), download.Attributes["necos.response.size"])
+	require.NotEqual(t, parent.SpanContext().TraceID, download.Context.TraceID)
+
+	failed := spans[2]
+	require.True(t, IsNotFound(failed.Err))
+	require.Equal(t, http.StatusNotFound, failed.Attributes["http.response.status_code"])
+
+	require.Equal(t, "test", spans[3].Name)
+	recorder.Reset()
+	require.Empty(t, recorder.Spans())
+}